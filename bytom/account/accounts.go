@@ -6,6 +6,8 @@ import (
 
 	"github.com/bytom-community/wasm/bytom/blockchain/signers"
 	"github.com/bytom-community/wasm/bytom/common"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
 )
 
 var (
@@ -18,11 +20,19 @@ func ContractKeyHexString(hash common.Hash) string {
 	return string(append(contractPrefix, []byte(str)...))
 }
 
-// Account is structure of Bytom account
+// Account is structure of Bytom account, the xpubs of watch-only account are
+// account-level xpubs derived from the root xpubs by the account path
 type Account struct {
 	*signers.Signer
-	ID    string `json:"id"`
-	Alias string `json:"alias"`
+	ID        string `json:"id"`
+	Alias     string `json:"alias"`
+	WatchOnly bool   `json:"watch_only,omitempty"`
+
+	// RootXPubs and AccountPath of watch-only account locate the keys of the
+	// account owner, so the templates created by the watch-only account can
+	// be signed by the keystore of the root keys
+	RootXPubs   []chainkd.XPub       `json:"root_xpubs,omitempty"`
+	AccountPath []chainjson.HexBytes `json:"account_path,omitempty"`
 }
 
 //CtrlProgram is structure of account control program
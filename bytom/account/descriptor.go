@@ -0,0 +1,288 @@
+package account
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/signers"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/crypto/sha3pool"
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
+// DescriptorVersion is the current version of account descriptor
+const DescriptorVersion = 1
+
+// the chains of account descriptor
+const (
+	ChainBytom = "bytom"
+	ChainVapor = "vapor"
+)
+
+const (
+	descriptorPrefix   = "account("
+	descriptorSuffix   = ")"
+	checksumSeparator  = "#"
+	xpubSeparator      = "+"
+	descriptorChecksum = 4
+
+	// descriptorReserved is the characters can't be in the field values
+	descriptorReserved = "(),=#+"
+)
+
+var (
+	// ErrBadDescriptor is returned when the account descriptor is malformed.
+	ErrBadDescriptor = errors.New("bad account descriptor")
+
+	// ErrDescriptorChecksum is returned when the checksum of account descriptor mismatch.
+	ErrDescriptorChecksum = errors.New("account descriptor checksum mismatch")
+
+	// ErrDescriptorVersion is returned when the version of account descriptor is unsupported.
+	ErrDescriptorVersion = errors.New("unsupported account descriptor version")
+)
+
+// Descriptor describes the signer of account for sharing between cosigners and
+// watch-only viewers, the xpubs are the root xpubs, or the account-level xpubs
+// of the watch-only account without root xpubs. It is encoded as:
+//   account(v=1,chain=bytom,network=mainnet,type=account,id=<id>,quorum=1,key_index=1,derive_rule=0,xpubs=<xpub>+<xpub>)#<checksum>
+// the checksum is the first 4 bytes of the sha3-256 hash of the part before '#'.
+type Descriptor struct {
+	Chain      string
+	Network    string
+	Type       string
+	ID         string
+	XPubs      []chainkd.XPub
+	Quorum     int
+	KeyIndex   uint64
+	DeriveRule uint8
+	WatchOnly  bool
+
+	// AccountXPubs tells the xpubs are the account-level xpubs in the
+	// order of the multisig program, only for the watch-only account
+	AccountXPubs bool
+}
+
+// NewDescriptor return the descriptor of account, the account ID is kept so
+// that the account of any ID scheme gets the same ID after import
+func NewDescriptor(acc *Account, chain, network string) (*Descriptor, error) {
+	if strings.ContainsAny(acc.ID, descriptorReserved) {
+		return nil, errors.WithDetailf(ErrBadDescriptor, "bad id %s", acc.ID)
+	}
+
+	rootXPubs := acc.XPubs
+	if acc.WatchOnly && len(acc.RootXPubs) == 0 {
+		xpubs := make([]chainkd.XPub, len(acc.XPubs))
+		copy(xpubs, acc.XPubs)
+		return &Descriptor{
+			Chain:        chain,
+			Network:      network,
+			Type:         acc.Type,
+			ID:           acc.ID,
+			XPubs:        xpubs,
+			Quorum:       acc.Quorum,
+			KeyIndex:     acc.KeyIndex,
+			DeriveRule:   signers.BIP0032,
+			WatchOnly:    true,
+			AccountXPubs: true,
+		}, nil
+	}
+
+	if acc.WatchOnly {
+		// the descriptor only carries the key index for the account path
+		rootSigner := &signers.Signer{KeyIndex: acc.KeyIndex}
+		if !equalPath(accountPath(acc), signers.Path(rootSigner, signers.AccountKeySpace)) {
+			return nil, errors.WithDetail(ErrBadWatchOnly, "account path mismatch the key index")
+		}
+		rootXPubs = acc.RootXPubs
+	}
+
+	xpubs := make([]chainkd.XPub, len(rootXPubs))
+	copy(xpubs, rootXPubs)
+	return &Descriptor{
+		Chain:      chain,
+		Network:    network,
+		Type:       acc.Type,
+		ID:         acc.ID,
+		XPubs:      xpubs,
+		Quorum:     acc.Quorum,
+		KeyIndex:   acc.KeyIndex,
+		DeriveRule: signers.BIP0032,
+		WatchOnly:  acc.WatchOnly,
+	}, nil
+}
+
+func equalPath(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Account return the account described by descriptor, the account ID is the
+// one of descriptor, or the deterministic one of the xpubs if it is missing
+func (d *Descriptor) Account(alias string) (*Account, error) {
+	xpubs := make([]chainkd.XPub, len(d.XPubs))
+	copy(xpubs, d.XPubs)
+	if d.AccountXPubs {
+		// the account-level xpubs are not sorted to keep the multisig program
+		signer := &signers.Signer{Type: d.Type, XPubs: xpubs, Quorum: d.Quorum, KeyIndex: d.KeyIndex}
+		acc := &Account{Signer: signer, ID: d.ID, Alias: alias}
+		if acc.ID == "" {
+			acc.ID = signers.DeterministicIDGenerate(signer.XPubs, signer.Quorum, signer.KeyIndex)
+		}
+		if err := ImportWatchOnly(acc); err != nil {
+			return nil, err
+		}
+		return acc, nil
+	}
+
+	signer, err := signers.Create(d.Type, xpubs, d.Quorum, d.KeyIndex)
+	if err != nil {
+		return nil, err
+	}
+	acc := &Account{Signer: signer, ID: d.ID, Alias: alias}
+	if acc.ID == "" {
+		acc.ID = signers.DeterministicIDGenerate(signer.XPubs, signer.Quorum, signer.KeyIndex)
+	}
+	if !d.WatchOnly {
+		return acc, nil
+	}
+	return ExportWatchOnly(acc, true)
+}
+
+// FormatDescriptor encode the descriptor into string, the root xpubs are sorted
+func FormatDescriptor(d *Descriptor) string {
+	xpubs := make([]chainkd.XPub, len(d.XPubs))
+	copy(xpubs, d.XPubs)
+	if !d.AccountXPubs {
+		sort.Slice(xpubs, func(i, j int) bool { return bytes.Compare(xpubs[i][:], xpubs[j][:]) < 0 })
+	}
+
+	xpubStrs := make([]string, 0, len(xpubs))
+	for _, xpub := range xpubs {
+		xpubStrs = append(xpubStrs, xpub.String())
+	}
+
+	fields := []string{
+		fmt.Sprintf("v=%d", DescriptorVersion),
+		"chain=" + d.Chain,
+		"network=" + d.Network,
+		"type=" + d.Type,
+	}
+	if d.ID != "" {
+		fields = append(fields, "id="+d.ID)
+	}
+	fields = append(fields,
+		fmt.Sprintf("quorum=%d", d.Quorum),
+		fmt.Sprintf("key_index=%d", d.KeyIndex),
+		fmt.Sprintf("derive_rule=%d", d.DeriveRule),
+	)
+	if d.WatchOnly {
+		fields = append(fields, "watch_only=1")
+	}
+	if d.AccountXPubs {
+		fields = append(fields, "account_xpubs=1")
+	}
+	fields = append(fields, "xpubs="+strings.Join(xpubStrs, xpubSeparator))
+
+	body := descriptorPrefix + strings.Join(fields, ",") + descriptorSuffix
+	return body + checksumSeparator + descriptorChecksumHex(body)
+}
+
+// ParseDescriptor decode the descriptor string and verify its checksum
+func ParseDescriptor(str string) (*Descriptor, error) {
+	str = strings.TrimSpace(str)
+	sep := strings.LastIndex(str, checksumSeparator)
+	if sep < 0 {
+		return nil, errors.WithDetail(ErrBadDescriptor, "missing checksum")
+	}
+
+	body, checksum := str[:sep], str[sep+1:]
+	if !strings.EqualFold(checksum, descriptorChecksumHex(body)) {
+		return nil, errors.Wrap(ErrDescriptorChecksum)
+	}
+	if !strings.HasPrefix(body, descriptorPrefix) || !strings.HasSuffix(body, descriptorSuffix) {
+		return nil, errors.WithDetail(ErrBadDescriptor, "missing account()")
+	}
+
+	fields := make(map[string]string)
+	for _, field := range strings.Split(body[len(descriptorPrefix):len(body)-len(descriptorSuffix)], ",") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.WithDetailf(ErrBadDescriptor, "bad field %s", field)
+		}
+		if _, ok := fields[kv[0]]; ok {
+			return nil, errors.WithDetailf(ErrBadDescriptor, "duplicated field %s", kv[0])
+		}
+		fields[kv[0]] = kv[1]
+	}
+
+	version, err := strconv.Atoi(fields["v"])
+	if err != nil {
+		return nil, errors.WithDetail(ErrBadDescriptor, "bad version")
+	}
+	if version != DescriptorVersion {
+		return nil, errors.WithDetailf(ErrDescriptorVersion, "version %d", version)
+	}
+
+	d := &Descriptor{
+		Chain:     fields["chain"],
+		Network:   fields["network"],
+		Type:      fields["type"],
+		ID:        fields["id"],
+		WatchOnly: fields["watch_only"] == "1",
+
+		AccountXPubs: fields["account_xpubs"] == "1",
+	}
+	if d.Chain != ChainBytom && d.Chain != ChainVapor {
+		return nil, errors.WithDetailf(ErrBadDescriptor, "bad chain %s", d.Chain)
+	}
+	if d.Network == "" {
+		return nil, errors.WithDetail(ErrBadDescriptor, "missing network")
+	}
+	if d.AccountXPubs && !d.WatchOnly {
+		return nil, errors.WithDetail(ErrBadDescriptor, "account xpubs without watch_only")
+	}
+	if d.Quorum, err = strconv.Atoi(fields["quorum"]); err != nil {
+		return nil, errors.WithDetail(ErrBadDescriptor, "bad quorum")
+	}
+	if d.KeyIndex, err = strconv.ParseUint(fields["key_index"], 10, 64); err != nil {
+		return nil, errors.WithDetail(ErrBadDescriptor, "bad key_index")
+	}
+	deriveRule, err := strconv.ParseUint(fields["derive_rule"], 10, 8)
+	if err != nil {
+		return nil, errors.WithDetail(ErrBadDescriptor, "bad derive_rule")
+	}
+	// the keys are derived by signers.Path which only follows BIP0032
+	if uint8(deriveRule) != signers.BIP0032 {
+		return nil, errors.WithDetailf(ErrBadDescriptor, "unsupported derive_rule %d", deriveRule)
+	}
+	d.DeriveRule = uint8(deriveRule)
+
+	if fields["xpubs"] == "" {
+		return nil, errors.Wrap(signers.ErrNoXPubs)
+	}
+	for _, xpubStr := range strings.Split(fields["xpubs"], xpubSeparator) {
+		var xpub chainkd.XPub
+		if err := xpub.UnmarshalText([]byte(xpubStr)); err != nil {
+			return nil, errors.WithDetailf(ErrBadDescriptor, "bad xpub %s", xpubStr)
+		}
+		d.XPubs = append(d.XPubs, xpub)
+	}
+	return d, nil
+}
+
+func descriptorChecksumHex(body string) string {
+	var hash [32]byte
+	sha3pool.Sum256(hash[:], []byte(body))
+	return hex.EncodeToString(hash[:descriptorChecksum])
+}
@@ -0,0 +1,147 @@
+package account
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/signers"
+	"github.com/bytom-community/wasm/bytom/crypto"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+)
+
+// descriptorRoundTrip formats the descriptor of account and imports it back
+func descriptorRoundTrip(t *testing.T, acc *Account) (*Descriptor, *Account) {
+	d, err := NewDescriptor(acc, ChainBytom, "mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseDescriptor(FormatDescriptor(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := parsed.Account("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed, imported
+}
+
+func TestDescriptorRoundTrip(t *testing.T) {
+	acc, _ := newTestAccount(t, 2, 3)
+	legacyAcc, _ := newTestAccount(t, 1, 1)
+	legacyAcc.ID = signers.IDGenerate()
+	deterministicAcc, _ := newTestAccount(t, 2, 2)
+	deterministicAcc.ID = signers.DeterministicIDGenerate(deterministicAcc.XPubs, deterministicAcc.Quorum, deterministicAcc.KeyIndex)
+
+	cases := []struct {
+		desc string
+		acc  *Account
+	}{
+		{desc: "account", acc: acc},
+		{desc: "legacy id account", acc: legacyAcc},
+		{desc: "deterministic id account", acc: deterministicAcc},
+		{desc: "watch-only account", acc: exportImport(t, acc, true)},
+		{desc: "watch-only account without root xpubs", acc: exportImport(t, acc, false)},
+		{desc: "legacy id watch-only account without root xpubs", acc: exportImport(t, legacyAcc, false)},
+	}
+
+	for _, c := range cases {
+		_, imported := descriptorRoundTrip(t, c.acc)
+		if imported.ID != c.acc.ID {
+			t.Errorf("%s: got id %s, want %s", c.desc, imported.ID, c.acc.ID)
+		}
+		if imported.WatchOnly != c.acc.WatchOnly {
+			t.Errorf("%s: got watch only %v, want %v", c.desc, imported.WatchOnly, c.acc.WatchOnly)
+		}
+		if len(imported.XPubs) != len(c.acc.XPubs) || len(imported.RootXPubs) != len(c.acc.RootXPubs) {
+			t.Fatalf("%s: got %d xpubs and %d root xpubs, want %d and %d", c.desc, len(imported.XPubs), len(imported.RootXPubs), len(c.acc.XPubs), len(c.acc.RootXPubs))
+		}
+		for j, xpub := range imported.XPubs {
+			if xpub != c.acc.XPubs[j] {
+				t.Errorf("%s: got xpub %d %s, want %s", c.desc, j, xpub.String(), c.acc.XPubs[j].String())
+			}
+		}
+	}
+}
+
+func TestDescriptorWithoutID(t *testing.T) {
+	acc, _ := newTestAccount(t, 2, 3)
+	d, err := NewDescriptor(acc, ChainBytom, "mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.ID = ""
+	parsed, err := ParseDescriptor(FormatDescriptor(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := parsed.Account("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := signers.DeterministicIDGenerate(acc.XPubs, acc.Quorum, acc.KeyIndex); imported.ID != want {
+		t.Errorf("got id %s, want %s", imported.ID, want)
+	}
+}
+
+func TestDescriptorAccountXPubs(t *testing.T) {
+	acc, _ := newTestAccount(t, 2, 3)
+	d, watchAcc := descriptorRoundTrip(t, exportImport(t, acc, false))
+	if !d.AccountXPubs || !d.WatchOnly {
+		t.Fatalf("got account xpubs %v and watch only %v, want true", d.AccountXPubs, d.WatchOnly)
+	}
+
+	// the account-level xpubs derive the same control program as the root ones
+	const index = 7
+	derivedXPubs := chainkd.DeriveXPubs(watchAcc.XPubs, DerivePath(watchAcc, index))
+	script, err := vmutil.P2SPMultiSigProgram(chainkd.XPubKeys(derivedXPubs), watchAcc.Quorum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := vmutil.P2WSHProgram(crypto.Sha256(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := controlProgram(t, acc, index); !bytes.Equal(program, want) {
+		t.Errorf("got control program %x, want %x", program, want)
+	}
+
+	if _, err := SigningInstruction(watchAcc, 0, index); errors.Root(err) != ErrNoRootXPubs {
+		t.Errorf("got error %v, want %v", err, ErrNoRootXPubs)
+	}
+}
+
+func TestParseDescriptorAccountXPubsWithoutWatchOnly(t *testing.T) {
+	acc, _ := newTestAccount(t, 1, 1)
+	d, err := NewDescriptor(acc, ChainBytom, "mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.AccountXPubs = true
+	if _, err := ParseDescriptor(FormatDescriptor(d)); errors.Root(err) != ErrBadDescriptor {
+		t.Errorf("got error %v, want %v", err, ErrBadDescriptor)
+	}
+}
+
+func TestParseDescriptorDeriveRule(t *testing.T) {
+	acc, _ := newTestAccount(t, 1, 1)
+	d, err := NewDescriptor(acc, ChainBytom, "mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.DeriveRule = signers.BIP0044
+	str := FormatDescriptor(d)
+	if !strings.Contains(str, "derive_rule=1") {
+		t.Fatalf("descriptor %s without derive_rule=1", str)
+	}
+	if _, err := ParseDescriptor(str); errors.Root(err) != ErrBadDescriptor {
+		t.Errorf("got error %v, want %v", err, ErrBadDescriptor)
+	}
+}
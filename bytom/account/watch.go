@@ -0,0 +1,171 @@
+package account
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/signers"
+	"github.com/bytom-community/wasm/bytom/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+)
+
+var (
+	// ErrWatchOnly is returned when a watch-only account is asked to sign.
+	ErrWatchOnly = errors.New("watch-only account has no private key to sign")
+
+	// ErrAlreadyWatchOnly is returned when exporting a watch-only account again.
+	ErrAlreadyWatchOnly = errors.New("account is already watch-only")
+
+	// ErrBadWatchOnly is returned when the root xpubs of watch-only account
+	// don't derive its account-level xpubs.
+	ErrBadWatchOnly = errors.New("root xpubs mismatch the watch-only account")
+
+	// ErrNoRootXPubs is returned when the watch-only account without root
+	// xpubs creates the signing instruction.
+	ErrNoRootXPubs = errors.New("watch-only account has no root xpubs to sign")
+
+	// ErrKeyNotInAccount is returned when the signing key is not a root key
+	// of the account.
+	ErrKeyNotInAccount = errors.New("key is not in the account")
+)
+
+// ExportWatchOnly converts the account into the watch-only one, the xpubs are
+// replaced by the account-level xpubs for the address derivation. The root
+// xpubs and the account path are only kept for the signing instructions if
+// withRootXPubs is true, since the root xpubs derive the addresses of all the
+// other accounts under them.
+func ExportWatchOnly(acc *Account, withRootXPubs bool) (*Account, error) {
+	if acc.WatchOnly {
+		return nil, errors.Wrap(ErrAlreadyWatchOnly)
+	}
+
+	// the xpubs order must be kept for the multisig program
+	accountPath := signers.Path(acc.Signer, signers.AccountKeySpace)
+	signer := &signers.Signer{
+		Type:     acc.Type,
+		XPubs:    chainkd.DeriveXPubs(acc.XPubs, accountPath),
+		Quorum:   acc.Quorum,
+		KeyIndex: acc.KeyIndex,
+	}
+	watchAcc := &Account{Signer: signer, ID: acc.ID, Alias: acc.Alias, WatchOnly: true}
+	if !withRootXPubs {
+		return watchAcc, nil
+	}
+
+	watchAcc.RootXPubs = make([]chainkd.XPub, len(acc.XPubs))
+	copy(watchAcc.RootXPubs, acc.XPubs)
+	for _, p := range accountPath {
+		watchAcc.AccountPath = append(watchAcc.AccountPath, p)
+	}
+	return watchAcc, nil
+}
+
+// ImportWatchOnly validates the exported watch-only account, the root xpubs
+// are optional, but they must derive the account-level xpubs if given
+func ImportWatchOnly(acc *Account) error {
+	if acc.Signer == nil || len(acc.XPubs) == 0 {
+		return errors.Wrap(signers.ErrNoXPubs)
+	}
+	if acc.Quorum == 0 || acc.Quorum > len(acc.XPubs) {
+		return errors.Wrap(signers.ErrBadQuorum)
+	}
+	for i := 0; i < len(acc.XPubs); i++ {
+		for j := i + 1; j < len(acc.XPubs); j++ {
+			if bytes.Equal(acc.XPubs[i][:], acc.XPubs[j][:]) {
+				return errors.WithDetailf(signers.ErrDupeXPub, "duplicated key=%x", acc.XPubs[i])
+			}
+		}
+	}
+
+	if len(acc.RootXPubs) > 0 || len(acc.AccountPath) > 0 {
+		if len(acc.RootXPubs) != len(acc.XPubs) || len(acc.AccountPath) == 0 {
+			return errors.Wrap(ErrBadWatchOnly)
+		}
+		for i, xpub := range chainkd.DeriveXPubs(acc.RootXPubs, accountPath(acc)) {
+			if xpub != acc.XPubs[i] {
+				return errors.WithDetailf(ErrBadWatchOnly, "root xpub %d", i)
+			}
+		}
+	}
+
+	acc.WatchOnly = true
+	return nil
+}
+
+// CheckSigningKey refuses the watch-only account to sign, and makes sure the
+// key is one of the root keys of the account
+func CheckSigningKey(acc *Account, xpub chainkd.XPub) error {
+	if acc.WatchOnly {
+		return errors.Wrap(ErrWatchOnly)
+	}
+	if acc.Signer == nil {
+		return errors.Wrap(signers.ErrNoXPubs)
+	}
+	for _, accXPub := range acc.XPubs {
+		if accXPub == xpub {
+			return nil
+		}
+	}
+	return errors.WithDetailf(ErrKeyNotInAccount, "xpub %s", xpub.String())
+}
+
+// DerivePath returns the path from the account xpubs to the key of the given index,
+// it is relative to the account-level xpubs for watch-only account.
+func DerivePath(acc *Account, index uint64) [][]byte {
+	if !acc.WatchOnly {
+		return signers.Path(acc.Signer, signers.AccountKeySpace, index)
+	}
+
+	var idxBytes [8]byte
+	binary.LittleEndian.PutUint64(idxBytes[:], index)
+	return [][]byte{idxBytes[:]}
+}
+
+// signingKeys returns the root xpubs and the complete path of the keys signing
+// for the given index
+func signingKeys(acc *Account, index uint64) ([]chainkd.XPub, [][]byte, error) {
+	if !acc.WatchOnly {
+		return acc.XPubs, signers.Path(acc.Signer, signers.AccountKeySpace, index), nil
+	}
+	if len(acc.RootXPubs) == 0 {
+		return nil, nil, errors.Wrap(ErrNoRootXPubs)
+	}
+
+	path := append(accountPath(acc), DerivePath(acc, index)...)
+	return acc.RootXPubs, path, nil
+}
+
+func accountPath(acc *Account) [][]byte {
+	path := make([][]byte, 0, len(acc.AccountPath))
+	for _, p := range acc.AccountPath {
+		path = append(path, p)
+	}
+	return path
+}
+
+// SigningInstruction returns the unsigned signing instruction for spending the
+// control program of the given index at the position of transaction
+func SigningInstruction(acc *Account, position uint32, index uint64) (*txbuilder.SigningInstruction, error) {
+	xpubs, path, err := signingKeys(acc, index)
+	if err != nil {
+		return nil, err
+	}
+
+	sigInst := &txbuilder.SigningInstruction{Position: position}
+	sigInst.AddRawWitnessKeys(xpubs, path, acc.Quorum)
+
+	derivedXPubs := chainkd.DeriveXPubs(xpubs, path)
+	if len(derivedXPubs) == 1 {
+		sigInst.AddDataWitness([]byte(derivedXPubs[0].PublicKey()))
+		return sigInst, nil
+	}
+
+	script, err := vmutil.P2SPMultiSigProgram(chainkd.XPubKeys(derivedXPubs), acc.Quorum)
+	if err != nil {
+		return nil, err
+	}
+	sigInst.AddDataWitness(script)
+	return sigInst, nil
+}
@@ -0,0 +1,175 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/signers"
+	"github.com/bytom-community/wasm/bytom/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
+	"github.com/bytom-community/wasm/bytom/protocol/validation"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+)
+
+func newTestAccount(t *testing.T, quorum, keys int) (*Account, map[chainkd.XPub]chainkd.XPrv) {
+	xprvs := make(map[chainkd.XPub]chainkd.XPrv)
+	xpubs := make([]chainkd.XPub, 0, keys)
+	for i := 0; i < keys; i++ {
+		xprv, xpub, err := chainkd.NewXKeys(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xprvs[xpub] = xprv
+		xpubs = append(xpubs, xpub)
+	}
+
+	signer, err := signers.Create("account", xpubs, quorum, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Account{Signer: signer, ID: "test-id", Alias: "alice"}, xprvs
+}
+
+// exportImport exports the account as watch-only one and imports it from json
+func exportImport(t *testing.T, acc *Account, withRootXPubs bool) *Account {
+	watchAcc, err := ExportWatchOnly(acc, withRootXPubs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(watchAcc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported := &Account{}
+	if err := json.Unmarshal(b, imported); err != nil {
+		t.Fatal(err)
+	}
+	if err := ImportWatchOnly(imported); err != nil {
+		t.Fatal(err)
+	}
+	return imported
+}
+
+// controlProgram returns the control program of the index derived by the root keys
+func controlProgram(t *testing.T, acc *Account, index uint64) []byte {
+	derivedXPubs := chainkd.DeriveXPubs(acc.XPubs, signers.Path(acc.Signer, signers.AccountKeySpace, index))
+	if len(derivedXPubs) == 1 {
+		program, err := vmutil.P2WPKHProgram(crypto.Ripemd160(derivedXPubs[0].PublicKey()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return program
+	}
+
+	script, err := vmutil.P2SPMultiSigProgram(chainkd.XPubKeys(derivedXPubs), acc.Quorum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := vmutil.P2WSHProgram(crypto.Sha256(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program
+}
+
+func TestWatchOnlyRoundTripSign(t *testing.T) {
+	cases := []struct {
+		quorum, keys int
+	}{
+		{quorum: 1, keys: 1},
+		{quorum: 2, keys: 3},
+	}
+
+	for i, c := range cases {
+		acc, xprvs := newTestAccount(t, c.quorum, c.keys)
+		watchAcc := exportImport(t, acc, true)
+
+		const index = 5
+		txData := types.TxData{
+			Version: 1,
+			Inputs:  []*types.TxInput{types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 10000, 0, controlProgram(t, acc, index))},
+			Outputs: []*types.TxOutput{types.NewTxOutput(*consensus.BTMAssetID, 9000, []byte{0x51})},
+		}
+
+		sigInst, err := SigningInstruction(watchAcc, 0, index)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		tpl := &txbuilder.Template{Transaction: types.NewTx(txData), SigningInstructions: []*txbuilder.SigningInstruction{sigInst}}
+
+		// sign with the root keys like the keystore of cold wallet
+		signFn := func(_ context.Context, xpub chainkd.XPub, path [][]byte, data [32]byte, _ string) ([]byte, error) {
+			xprv, ok := xprvs[xpub]
+			if !ok {
+				return nil, errors.New("unknown xpub")
+			}
+			return xprv.Derive(path).Sign(data[:]), nil
+		}
+		if err := txbuilder.Sign(context.Background(), tpl, "", signFn); err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+
+		if _, err := validation.SimulateInput(&tpl.Transaction.TxData, 0, tpl.Transaction.Inputs[0].Arguments(), 1, 100000); err != nil {
+			t.Errorf("case %d: the signed input fails to verify: %v", i, err)
+		}
+	}
+}
+
+func TestWatchOnlyWithoutRootXPubs(t *testing.T) {
+	acc, _ := newTestAccount(t, 1, 1)
+	watchAcc := exportImport(t, acc, false)
+	if len(watchAcc.RootXPubs) != 0 || len(watchAcc.AccountPath) != 0 {
+		t.Fatalf("got root xpubs %v and account path %v, want none", watchAcc.RootXPubs, watchAcc.AccountPath)
+	}
+	if watchAcc.XPubs[0] == acc.XPubs[0] {
+		t.Errorf("the root xpub is exported as the account xpub")
+	}
+
+	if _, err := SigningInstruction(watchAcc, 0, 1); errors.Root(err) != ErrNoRootXPubs {
+		t.Errorf("got error %v, want %v", err, ErrNoRootXPubs)
+	}
+}
+
+func TestImportWatchOnlyBadRootXPubs(t *testing.T) {
+	acc, _ := newTestAccount(t, 1, 1)
+	other, _ := newTestAccount(t, 1, 1)
+	watchAcc := exportImport(t, acc, true)
+	watchAcc.RootXPubs = other.XPubs
+
+	if err := ImportWatchOnly(watchAcc); errors.Root(err) != ErrBadWatchOnly {
+		t.Errorf("got error %v, want %v", err, ErrBadWatchOnly)
+	}
+}
+
+func TestCheckSigningKey(t *testing.T) {
+	acc, _ := newTestAccount(t, 1, 1)
+	_, otherXPub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchAcc := exportImport(t, acc, true)
+
+	cases := []struct {
+		acc  *Account
+		xpub chainkd.XPub
+		want error
+	}{
+		{acc: acc, xpub: acc.XPubs[0], want: nil},
+		{acc: acc, xpub: otherXPub, want: ErrKeyNotInAccount},
+		{acc: watchAcc, xpub: acc.XPubs[0], want: ErrWatchOnly},
+		{acc: watchAcc, xpub: watchAcc.XPubs[0], want: ErrWatchOnly},
+	}
+
+	for i, c := range cases {
+		if err := CheckSigningKey(c.acc, c.xpub); errors.Root(err) != c.want {
+			t.Errorf("case %d: got error %v, want %v", i, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package blockchain provides the helpers to describe bytom chain data.
+package blockchain
+
+import (
+	"github.com/bytom-community/wasm/bytom/common"
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/consensus/segwit"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/sdk/classify"
+)
+
+// the classification of control program
+const (
+	ProgramP2WPKH      = "p2wpkh"
+	ProgramP2WSH       = "p2wsh"
+	ProgramP2PKH       = "p2pkh"
+	ProgramP2SH        = "p2sh"
+	ProgramRetire      = "retire"
+	ProgramCoinbase    = "coinbase"
+	ProgramMultiSig    = "multisig"
+	ProgramContract    = "contract"
+	ProgramNonStandard = "nonstandard"
+	ProgramUnknown     = "unknown"
+)
+
+// programTypes maps the types of the classifier to the classification
+var programTypes = map[string]string{
+	classify.TypeP2WPKH:   ProgramP2WPKH,
+	classify.TypeP2WSH:    ProgramP2WSH,
+	classify.TypeP2PKH:    ProgramP2PKH,
+	classify.TypeP2SH:     ProgramP2SH,
+	classify.TypeRetire:   ProgramRetire,
+	classify.TypeCoinbase: ProgramCoinbase,
+	classify.TypeMultiSig: ProgramMultiSig,
+	classify.TypeTemplate: ProgramContract,
+}
+
+// GetAddressFromControlProgram return the address of the standard control program,
+// and empty string for the non-standard one
+func GetAddressFromControlProgram(prog []byte, netParams *consensus.Params) string {
+	if segwit.IsP2WPKHScript(prog) {
+		if pubHash, err := segwit.GetHashFromStandardProg(prog); err == nil {
+			return buildP2PKHAddress(pubHash, netParams)
+		}
+	} else if segwit.IsP2WSHScript(prog) {
+		if scriptHash, err := segwit.GetHashFromStandardProg(prog); err == nil {
+			return buildP2SHAddress(scriptHash, netParams)
+		}
+	}
+	return ""
+}
+
+// GetControlProgramType return the classification of control program, the
+// contract is the program instantiated from the standard contract template,
+// and the program can't be parsed is unknown
+func GetControlProgramType(prog []byte) string {
+	if _, err := vm.ParseProgram(prog); err != nil {
+		return ProgramUnknown
+	}
+
+	p, err := classify.Classify(classify.ChainBytom, prog)
+	if err != nil {
+		return ProgramUnknown
+	}
+	if t, ok := programTypes[p.Type]; ok {
+		return t
+	}
+	return ProgramNonStandard
+}
+
+func buildP2PKHAddress(pubHash []byte, netParams *consensus.Params) string {
+	address, err := common.NewAddressWitnessPubKeyHash(pubHash, netParams)
+	if err != nil {
+		return ""
+	}
+	return address.EncodeAddress()
+}
+
+func buildP2SHAddress(scriptHash []byte, netParams *consensus.Params) string {
+	address, err := common.NewAddressWitnessScriptHash(scriptHash, netParams)
+	if err != nil {
+		return ""
+	}
+	return address.EncodeAddress()
+}
@@ -0,0 +1,39 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+)
+
+func TestGetControlProgramType(t *testing.T) {
+	hash := make([]byte, 20)
+	p2wpkh, err := vmutil.P2WPKHProgram(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retire, err := vmutil.RetireProgram([]byte("comment"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		desc    string
+		program []byte
+		want    string
+	}{
+		{desc: "p2wpkh", program: p2wpkh, want: ProgramP2WPKH},
+		{desc: "retire", program: retire, want: ProgramRetire},
+		{desc: "fail", program: []byte{byte(vm.OP_FAIL)}, want: ProgramRetire},
+		{desc: "coinbase", program: []byte{byte(vm.OP_TRUE)}, want: ProgramCoinbase},
+		{desc: "nonstandard", program: []byte{byte(vm.OP_1), byte(vm.OP_1), byte(vm.OP_ADD)}, want: ProgramNonStandard},
+		{desc: "bad pushdata", program: []byte{byte(vm.OP_PUSHDATA1)}, want: ProgramUnknown},
+	}
+
+	for _, c := range cases {
+		if got := GetControlProgramType(c.program); got != c.want {
+			t.Errorf("%s: got type %s, want %s", c.desc, got, c.want)
+		}
+	}
+}
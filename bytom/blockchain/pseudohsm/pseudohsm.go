@@ -8,4 +8,5 @@ import (
 // pre-define errors for supporting bytom errorFormatter
 var (
 	ErrDecrypt = errors.New("could not decrypt key with given passphrase")
+	ErrLoadKey = errors.New("key not found or wrong password")
 )
@@ -0,0 +1,36 @@
+package pseudohsm
+
+import (
+	"context"
+
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
+// KeySigner signs with the decrypted key of the local keystore.
+type KeySigner struct {
+	key *XKey
+}
+
+// NewKeySigner create the signer of the decrypted key.
+func NewKeySigner(key *XKey) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+// XPubs return the xpub of the key.
+func (s *KeySigner) XPubs() []chainkd.XPub {
+	return []chainkd.XPub{s.key.XPub}
+}
+
+// Sign return the signature of hash made by the key derived by the path.
+func (s *KeySigner) Sign(_ context.Context, xpub chainkd.XPub, path [][]byte, hash [32]byte) ([]byte, error) {
+	if xpub != s.key.XPub {
+		return nil, errors.Wrap(ErrLoadKey)
+	}
+
+	xprv := s.key.XPrv
+	if len(path) > 0 {
+		xprv = xprv.Derive(path)
+	}
+	return xprv.Sign(hash[:]), nil
+}
@@ -0,0 +1,64 @@
+package remotesigner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+)
+
+// signer is the local signer served by the Handler, txbuilder.Signer
+// satisfies it.
+type signer interface {
+	Sign(ctx context.Context, xpub chainkd.XPub, path [][]byte, hash [32]byte) ([]byte, error)
+}
+
+// Handler serves the remote signer protocol with the local signer, it can be
+// used as the stub of the remote signer.
+type Handler struct {
+	signer signer
+}
+
+// NewHandler create the handler signing with the signer.
+func NewHandler(s signer) *Handler {
+	return &Handler{signer: s}
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(w, http.StatusMethodNotAllowed, &SignResponse{Error: "method not allowed"})
+		return
+	}
+
+	var req SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(w, http.StatusBadRequest, &SignResponse{Error: err.Error()})
+		return
+	}
+	if len(req.Hash) != 32 {
+		writeResponse(w, http.StatusBadRequest, &SignResponse{Error: "invalid hash"})
+		return
+	}
+
+	path := make([][]byte, 0, len(req.DerivationPath))
+	for _, p := range req.DerivationPath {
+		path = append(path, p)
+	}
+	var hash [32]byte
+	copy(hash[:], req.Hash)
+
+	sig, err := h.signer.Sign(r.Context(), req.XPub, path, hash)
+	if err != nil {
+		writeResponse(w, http.StatusForbidden, &SignResponse{Error: err.Error()})
+		return
+	}
+	writeResponse(w, http.StatusOK, &SignResponse{Signature: sig})
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp *SignResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
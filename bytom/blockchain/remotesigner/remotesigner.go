@@ -0,0 +1,91 @@
+// Package remotesigner implements the HTTP protocol of the remote signer.
+//
+// The client posts the json request to the url of signer:
+//   {"xpub": "<xpub>", "derivation_path": ["<hex>", ...], "hash": "<hex>"}
+// and the signer responds with status 200 and the signature:
+//   {"signature": "<hex>"}
+// or with another status and the error:
+//   {"error": "<message>"}
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
+// ErrRemoteSign means the remote signer refused to sign
+var ErrRemoteSign = errors.New("remote signer failed to sign")
+
+// SignRequest is the request of the remote signer.
+type SignRequest struct {
+	XPub           chainkd.XPub         `json:"xpub"`
+	DerivationPath []chainjson.HexBytes `json:"derivation_path"`
+	Hash           chainjson.HexBytes   `json:"hash"`
+}
+
+// SignResponse is the response of the remote signer.
+type SignResponse struct {
+	Signature chainjson.HexBytes `json:"signature,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// Client is the signer backed by the remote signer at the url.
+type Client struct {
+	url    string
+	xpubs  []chainkd.XPub
+	client *http.Client
+}
+
+// NewClient create the remote signer owning the xpubs, the http.DefaultClient
+// is used if the client is nil.
+func NewClient(url string, xpubs []chainkd.XPub, client *http.Client) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Client{url: url, xpubs: xpubs, client: client}
+}
+
+// XPubs return the xpubs owned by the remote signer.
+func (c *Client) XPubs() []chainkd.XPub {
+	return c.xpubs
+}
+
+// Sign post the sign request to the remote signer.
+func (c *Client) Sign(ctx context.Context, xpub chainkd.XPub, path [][]byte, hash [32]byte) ([]byte, error) {
+	req := &SignRequest{XPub: xpub, DerivationPath: make([]chainjson.HexBytes, 0, len(path)), Hash: hash[:]}
+	for _, p := range path {
+		req.DerivationPath = append(req.DerivationPath, p)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.client.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "post sign request")
+	}
+	defer httpResp.Body.Close()
+
+	var resp SignResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, errors.Wrap(err, "decode sign response")
+	}
+	if httpResp.StatusCode != http.StatusOK || resp.Error != "" {
+		return nil, errors.WithDetailf(ErrRemoteSign, "status %d: %s", httpResp.StatusCode, resp.Error)
+	}
+	return resp.Signature, nil
+}
@@ -0,0 +1,127 @@
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/pseudohsm"
+	"github.com/bytom-community/wasm/bytom/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, chainkd.XPub) {
+	xprv, xpub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(NewHandler(pseudohsm.NewKeySigner(&pseudohsm.XKey{XPrv: xprv, XPub: xpub})))
+	return server, xpub
+}
+
+func TestClientSign(t *testing.T) {
+	server, xpub := newTestServer(t)
+	defer server.Close()
+
+	_, otherXPub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := [][]byte{{0x01, 0x02}, {0x03}}
+	hash := [32]byte{0x01, 0x02, 0x03}
+	client := NewClient(server.URL, []chainkd.XPub{xpub}, server.Client())
+
+	sig, err := client.Sign(context.Background(), xpub, path, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !xpub.Derive(path).Verify(hash[:], sig) {
+		t.Errorf("invalid signature %x", sig)
+	}
+
+	if _, err := client.Sign(context.Background(), otherXPub, path, hash); errors.Root(err) != ErrRemoteSign {
+		t.Errorf("got error %v, want %v", err, ErrRemoteSign)
+	}
+}
+
+func TestHandlerBadRequest(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	cases := []struct {
+		method string
+		body   string
+		want   int
+	}{
+		{method: http.MethodGet, want: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, body: "{", want: http.StatusBadRequest},
+		{method: http.MethodPost, body: `{"hash": "0102"}`, want: http.StatusBadRequest},
+	}
+
+	for i, c := range cases {
+		req, err := http.NewRequest(c.method, server.URL, bytes.NewReader([]byte(c.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != c.want {
+			t.Errorf("case %d: got status %d, want %d", i, resp.StatusCode, c.want)
+		}
+	}
+}
+
+func TestSignTemplateByRemoteSigner(t *testing.T) {
+	server, xpub := newTestServer(t)
+	defer server.Close()
+
+	_, otherXPub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newTemplate := func() *txbuilder.Template {
+		txData := types.TxData{
+			Version: 1,
+			Inputs:  []*types.TxInput{types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 10000, 0, []byte{byte(vm.OP_TRUE)})},
+			Outputs: []*types.TxOutput{types.NewTxOutput(*consensus.BTMAssetID, 9000, []byte{byte(vm.OP_TRUE)})},
+		}
+		sigInst := &txbuilder.SigningInstruction{}
+		sigInst.AddRawWitnessKeys([]chainkd.XPub{xpub, otherXPub}, [][]byte{{0x01}}, 1)
+		return &txbuilder.Template{Transaction: types.NewTx(txData), SigningInstructions: []*txbuilder.SigningInstruction{sigInst}}
+	}
+
+	// the key of other xpub is not owned by any signer, so it is left unsigned
+	registry := txbuilder.NewSignerRegistry()
+	if err := registry.Register(NewClient(server.URL, []chainkd.XPub{xpub}, server.Client())); err != nil {
+		t.Fatal(err)
+	}
+	tpl := newTemplate()
+	if err := txbuilder.Sign(context.Background(), tpl, "", registry.SignFunc()); err != nil {
+		t.Fatal(err)
+	}
+	if !txbuilder.SignProgress(tpl) {
+		t.Errorf("the template is not signed by the remote signer")
+	}
+
+	// the remote signer refuses the key of other xpub, and the error is returned
+	badRegistry := txbuilder.NewSignerRegistry()
+	if err := badRegistry.Register(NewClient(server.URL, []chainkd.XPub{otherXPub}, server.Client())); err != nil {
+		t.Fatal(err)
+	}
+	if err := txbuilder.Sign(context.Background(), newTemplate(), "", badRegistry.SignFunc()); errors.Root(err) != ErrRemoteSign {
+		t.Errorf("got error %v, want %v", err, ErrRemoteSign)
+	}
+}
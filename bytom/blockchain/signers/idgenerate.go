@@ -1,11 +1,22 @@
 package signers
 
 import (
+	"bytes"
 	"encoding/binary"
+	"sort"
 	"sync/atomic"
 	"time"
 
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/crypto/sha3pool"
 	"github.com/bytom-community/wasm/bytom/encoding/base32"
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
+// the schemes of signer id
+const (
+	LegacyIDScheme        = "legacy"
+	DeterministicIDScheme = "deterministic"
 )
 
 //1<seq_id ,increase by 1
@@ -39,3 +50,37 @@ func IDGenerate() string {
 	return encodeString
 
 }
+
+// DeterministicIDGenerate generate signer id from the sorted xpubs, quorum and key index,
+// so that the same signer restored on different devices gets the same id
+func DeterministicIDGenerate(xpubs []chainkd.XPub, quorum int, keyIndex uint64) string {
+	sortedXPubs := make([]chainkd.XPub, len(xpubs))
+	copy(sortedXPubs, xpubs)
+	sort.Sort(sortKeys(sortedXPubs))
+
+	var buf bytes.Buffer
+	for _, xpub := range sortedXPubs {
+		buf.Write(xpub[:])
+	}
+	var num [8]byte
+	binary.LittleEndian.PutUint64(num[:], uint64(quorum))
+	buf.Write(num[:])
+	binary.LittleEndian.PutUint64(num[:], keyIndex)
+	buf.Write(num[:])
+
+	var hash [32]byte
+	sha3pool.Sum256(hash[:], buf.Bytes())
+	return base32.HexEncoding.WithPadding(base32.NoPadding).EncodeToString(hash[:16])
+}
+
+// GenerateID generate the signer id with the given scheme, the legacy scheme is used by default
+func GenerateID(scheme string, s *Signer) (string, error) {
+	switch scheme {
+	case "", LegacyIDScheme:
+		return IDGenerate(), nil
+	case DeterministicIDScheme:
+		return DeterministicIDGenerate(s.XPubs, s.Quorum, s.KeyIndex), nil
+	default:
+		return "", errors.WithDetailf(ErrBadIDScheme, "id scheme=%s", scheme)
+	}
+}
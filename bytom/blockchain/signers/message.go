@@ -0,0 +1,30 @@
+package signers
+
+import (
+	"encoding/binary"
+
+	"github.com/bytom-community/wasm/bytom/crypto/sha3pool"
+)
+
+// MessagePrefix is prepended to the signed message for domain separation, so
+// the signature of a message can't be confused with a transaction sighash.
+const MessagePrefix = "Bytom Signed Message:\n"
+
+// MessageHash return the hash of the signed message, both the prefix and the
+// message are encoded with the varint length ahead.
+func MessageHash(message []byte) [32]byte {
+	var buf [binary.MaxVarintLen64]byte
+	h := sha3pool.Get256()
+	defer sha3pool.Put256(h)
+
+	n := binary.PutUvarint(buf[:], uint64(len(MessagePrefix)))
+	h.Write(buf[:n])
+	h.Write([]byte(MessagePrefix))
+	n = binary.PutUvarint(buf[:], uint64(len(message)))
+	h.Write(buf[:n])
+	h.Write(message)
+
+	var hash [32]byte
+	h.Read(hash[:])
+	return hash
+}
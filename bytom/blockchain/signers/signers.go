@@ -17,6 +17,12 @@ const (
 	AccountKeySpace keySpace = 1
 )
 
+// the rules of key derivation, only BIP0032 is used by Path
+const (
+	BIP0032 uint8 = iota
+	BIP0044
+)
+
 var (
 	// ErrBadQuorum is returned by Create when the quorum
 	// provided is less than 1 or greater than the number
@@ -30,6 +36,10 @@ var (
 	// ErrDupeXPub is returned by create when the same xpub
 	// appears twice in a single call.
 	ErrDupeXPub = errors.New("xpubs cannot contain the same key more than once")
+
+	// ErrBadIDScheme is returned by GenerateID when the id scheme
+	// is neither legacy nor deterministic.
+	ErrBadIDScheme = errors.New("id scheme must be legacy or deterministic")
 )
 
 // Signer is the abstract concept of a signer,
@@ -0,0 +1,83 @@
+package blockchain
+
+import (
+	"github.com/bytom-community/wasm/bytom/common/arithmetic"
+	"github.com/bytom-community/wasm/bytom/consensus"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
+)
+
+// the type of transaction input
+const (
+	InputSpend    = "spend"
+	InputIssue    = "issue"
+	InputCoinbase = "coinbase"
+)
+
+// TxSummary is the human-readable summary of transaction, which is shown to
+// the user for confirmation before signing.
+type TxSummary struct {
+	ID      bc.Hash         `json:"tx_id"`
+	Inputs  []*SummaryEntry `json:"inputs"`
+	Outputs []*SummaryEntry `json:"outputs"`
+	Fee     uint64          `json:"fee"`
+}
+
+// SummaryEntry describes an input or output of transaction, the type of input
+// is spend, issue or coinbase, and the type of output is the classification of
+// its control program.
+type SummaryEntry struct {
+	Position       int                `json:"position"`
+	Type           string             `json:"type"`
+	AssetID        bc.AssetID         `json:"asset_id"`
+	Amount         uint64             `json:"amount"`
+	ControlProgram chainjson.HexBytes `json:"control_program,omitempty"`
+	Address        string             `json:"address,omitempty"`
+}
+
+// BuildTxSummary build the summary of transaction, the addresses are encoded
+// for the network.
+func BuildTxSummary(tx *types.Tx, netParams *consensus.Params) (*TxSummary, error) {
+	fee, err := arithmetic.CalculateTxFee(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &TxSummary{
+		ID:      tx.ID,
+		Inputs:  make([]*SummaryEntry, 0, len(tx.Inputs)),
+		Outputs: make([]*SummaryEntry, 0, len(tx.Outputs)),
+		Fee:     fee,
+	}
+	for i, input := range tx.Inputs {
+		entry := &SummaryEntry{Position: i}
+		switch input.InputType() {
+		case types.SpendInputType:
+			entry.Type = InputSpend
+			entry.ControlProgram = input.ControlProgram()
+			entry.Address = GetAddressFromControlProgram(entry.ControlProgram, netParams)
+		case types.IssuanceInputType:
+			entry.Type = InputIssue
+		case types.CoinbaseInputType:
+			entry.Type = InputCoinbase
+			summary.Inputs = append(summary.Inputs, entry)
+			continue
+		}
+
+		entry.AssetID, entry.Amount = input.AssetID(), input.Amount()
+		summary.Inputs = append(summary.Inputs, entry)
+	}
+
+	for i, output := range tx.Outputs {
+		summary.Outputs = append(summary.Outputs, &SummaryEntry{
+			Position:       i,
+			Type:           GetControlProgramType(output.ControlProgram),
+			AssetID:        *output.AssetId,
+			Amount:         output.Amount,
+			ControlProgram: output.ControlProgram,
+			Address:        GetAddressFromControlProgram(output.ControlProgram, netParams),
+		})
+	}
+	return summary, nil
+}
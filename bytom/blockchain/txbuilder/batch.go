@@ -0,0 +1,91 @@
+package txbuilder
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+)
+
+// BatchResult is the result of signing a template in batch.
+type BatchResult struct {
+	Index        int
+	Template     *Template
+	SignComplete bool
+	Err          error
+}
+
+// SignBatch signs the templates concurrently with at most workers goroutines,
+// the GOMAXPROCS is used if workers is not positive. The signFn must be safe
+// for concurrent use. The progress is called in the calling goroutine with the
+// result of each template in the order of completion, and the results are
+// returned in the order of templates.
+func SignBatch(ctx context.Context, tpls []*Template, auth string, signFn SignFunc, workers int, progress func(*BatchResult)) []*BatchResult {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan int)
+	done := make(chan *BatchResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				res := &BatchResult{Index: index, Template: tpls[index]}
+				if res.Err = Sign(ctx, tpls[index], auth, signFn); res.Err == nil {
+					res.SignComplete = SignProgress(tpls[index])
+				}
+				done <- res
+			}
+		}()
+	}
+
+	go func() {
+		for i := range tpls {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	results := make([]*BatchResult, len(tpls))
+	for res := range done {
+		results[res.Index] = res
+		if progress != nil {
+			progress(res)
+		}
+	}
+	return results
+}
+
+// RequiredXPubs returns the distinct xpubs of the keys to sign the templates.
+func RequiredXPubs(tpls []*Template) []chainkd.XPub {
+	var xpubs []chainkd.XPub
+	seen := make(map[chainkd.XPub]bool)
+	add := func(keys []keyID) {
+		for _, k := range keys {
+			if !seen[k.XPub] {
+				seen[k.XPub] = true
+				xpubs = append(xpubs, k.XPub)
+			}
+		}
+	}
+
+	for _, tpl := range tpls {
+		for _, sigInst := range tpl.SigningInstructions {
+			for _, wc := range sigInst.WitnessComponents {
+				switch sw := wc.(type) {
+				case *SignatureWitness:
+					add(sw.Keys)
+				case *RawTxSigWitness:
+					add(sw.Keys)
+				}
+			}
+		}
+	}
+	return xpubs
+}
@@ -1,6 +1,7 @@
 package txbuilder
 
 import (
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
 	"github.com/bytom-community/wasm/bytom/protocol/bc"
 	"github.com/bytom-community/wasm/bytom/protocol/vm"
 	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
@@ -16,6 +17,36 @@ type constraint interface {
 	code() []byte
 }
 
+// SigConstraints are the custom constraints of the signature program, which
+// are committed by the signed input when the template allows additional actions.
+type SigConstraints struct {
+	// MinBlockHeight and MaxBlockHeight limit the block height the transaction
+	// can be packed in, zero means no limit.
+	MinBlockHeight uint64 `json:"min_block_height"`
+	MaxBlockHeight uint64 `json:"max_block_height"`
+
+	// SpentOutputID is the outputID the input must spend, if it is nil the
+	// spent outputID of the current input is used.
+	SpentOutputID *bc.Hash `json:"spent_output_id"`
+
+	// Outputs are the outputs the transaction must include, if it is nil
+	// the existing outputs of the transaction are used.
+	Outputs []*OutputConstraint `json:"outputs"`
+}
+
+// InputSigConstraints are the custom constraints of the inputs keyed by the
+// position of input, the input without custom constraints commits to its spent
+// output and the existing outputs of transaction.
+type InputSigConstraints map[uint32]*SigConstraints
+
+// OutputConstraint describes an output the transaction must include at the given index.
+type OutputConstraint struct {
+	Index          int                `json:"index"`
+	AssetID        bc.AssetID         `json:"asset_id"`
+	Amount         uint64             `json:"amount"`
+	ControlProgram chainjson.HexBytes `json:"control_program"`
+}
+
 // outpointConstraint requires the outputID (and therefore, the outpoint) being spent to equal the
 // given value.
 type outputIDConstraint bc.Hash
@@ -45,3 +76,27 @@ func (p payConstraint) code() []byte {
 	prog, _ := builder.Build() // error is impossible
 	return prog
 }
+
+// heightConstraint requires the block height the transaction packed in
+// to be within the given range, zero means no limit.
+type heightConstraint struct {
+	Min uint64
+	Max uint64
+}
+
+func (h heightConstraint) code() []byte {
+	builder := vmutil.NewBuilder()
+	switch {
+	case h.Min > 0 && h.Max > 0:
+		builder.AddOp(vm.OP_BLOCKHEIGHT).AddInt64(int64(h.Min)).AddInt64(int64(h.Max) + 1)
+		builder.AddOp(vm.OP_WITHIN)
+	case h.Min > 0:
+		builder.AddOp(vm.OP_BLOCKHEIGHT).AddInt64(int64(h.Min))
+		builder.AddOp(vm.OP_GREATERTHANOREQUAL)
+	default:
+		builder.AddOp(vm.OP_BLOCKHEIGHT).AddInt64(int64(h.Max))
+		builder.AddOp(vm.OP_LESSTHANOREQUAL)
+	}
+	prog, _ := builder.Build() // error is impossible
+	return prog
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
 )
 
 // DataWitness used sign transaction
@@ -25,3 +26,20 @@ func (dw DataWitness) MarshalJSON() ([]byte, error) {
 	}
 	return json.Marshal(x)
 }
+
+// UnmarshalJSON unmarshal DataWitness
+func (dw *DataWitness) UnmarshalJSON(b []byte) error {
+	var x struct {
+		Type  string             `json:"type"`
+		Value chainjson.HexBytes `json:"value"`
+	}
+	if err := json.Unmarshal(b, &x); err != nil {
+		return err
+	}
+	if x.Type != "data" {
+		return errors.WithDetailf(ErrBadWitnessComponent, "type %s is not data", x.Type)
+	}
+
+	*dw = DataWitness(x.Value)
+	return nil
+}
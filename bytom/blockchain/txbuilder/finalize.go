@@ -9,4 +9,6 @@ var (
 	ErrMissingRawTx = errors.New("missing raw tx")
 	// ErrBadInstructionCount means too many signing instructions compare with inputs
 	ErrBadInstructionCount = errors.New("too many signing instructions in template")
+	// ErrBadWitnessComponent means the type of witness component is unknown
+	ErrBadWitnessComponent = errors.New("invalid witness component")
 )
@@ -0,0 +1,183 @@
+package txbuilder
+
+import (
+	"bytes"
+
+	"github.com/bytom-community/wasm/bytom/crypto/sha3pool"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
+var (
+	// ErrTemplateMismatch means the templates to merge are not for the same transaction
+	ErrTemplateMismatch = errors.New("templates mismatch")
+	// ErrSignatureConflict means the templates to merge have different signatures for the same key
+	ErrSignatureConflict = errors.New("signature conflict")
+	// ErrBadMergeSignature means the signature to merge is not made by its key
+	ErrBadMergeSignature = errors.New("invalid signature to merge")
+)
+
+// InputSignProgress is the quorum progress of the signing instruction
+type InputSignProgress struct {
+	Position uint32 `json:"position"`
+	Signed   int    `json:"signed"`
+	Quorum   int    `json:"quorum"`
+	Complete bool   `json:"complete"`
+}
+
+// SignProgressDetail return the quorum progress of each signing instruction
+func SignProgressDetail(txTemplate *Template) []*InputSignProgress {
+	progress := make([]*InputSignProgress, 0, len(txTemplate.SigningInstructions))
+	for _, sigInst := range txTemplate.SigningInstructions {
+		p := &InputSignProgress{Position: sigInst.Position, Complete: true}
+		for _, wc := range sigInst.WitnessComponents {
+			var signed, quorum int
+			switch sw := wc.(type) {
+			case *SignatureWitness:
+				signed, quorum = signedCount(sw.Sigs), sw.Quorum
+			case *RawTxSigWitness:
+				signed, quorum = signedCount(sw.Sigs), sw.Quorum
+			default:
+				continue
+			}
+
+			p.Signed += signed
+			p.Quorum += quorum
+			if signed < quorum {
+				p.Complete = false
+			}
+		}
+		progress = append(progress, p)
+	}
+	return progress
+}
+
+// MergeTemplates merges the signatures of src into dst, both templates must be
+// built for the same transaction with the same signing instructions. Each
+// signature must be made by its key over the sighash of the input or the hash
+// of the signature program, so a faulty cosigner can't fill the slot of other
+// keys. The witnesses of dst are materialized again after merging.
+//
+// The signFn of Sign only signs with the keys it owns, so each cosigner of a
+// multiple-sign account fills the signatures of its own keys and keeps the
+// others untouched, and their templates are merged here.
+func MergeTemplates(dst, src *Template) error {
+	if dst.Transaction == nil || src.Transaction == nil {
+		return errors.Wrap(ErrMissingRawTx)
+	}
+	if dst.Transaction.ID != src.Transaction.ID {
+		return errors.WithDetail(ErrTemplateMismatch, "transaction id")
+	}
+	if dst.AllowAdditional != src.AllowAdditional {
+		return errors.WithDetail(ErrTemplateMismatch, "allow additional actions")
+	}
+	if len(dst.SigningInstructions) != len(src.SigningInstructions) {
+		return errors.WithDetail(ErrTemplateMismatch, "signing instruction count")
+	}
+
+	for i, dstSigInst := range dst.SigningInstructions {
+		srcSigInst := src.SigningInstructions[i]
+		if dstSigInst.Position != srcSigInst.Position || len(dstSigInst.WitnessComponents) != len(srcSigInst.WitnessComponents) {
+			return errors.WithDetailf(ErrTemplateMismatch, "signing instruction %d", i)
+		}
+
+		for j, dstWC := range dstSigInst.WitnessComponents {
+			if err := mergeWitnessComponent(dst, dstSigInst.Position, dstWC, srcSigInst.WitnessComponents[j]); err != nil {
+				return errors.WithDetailf(err, "witness component %d of signing instruction %d", j, i)
+			}
+		}
+	}
+	return materializeWitnesses(dst)
+}
+
+func mergeWitnessComponent(tpl *Template, position uint32, dst, src witnessComponent) error {
+	switch dw := dst.(type) {
+	case *SignatureWitness:
+		sw, ok := src.(*SignatureWitness)
+		if !ok || dw.Quorum != sw.Quorum || !sameKeys(dw.Keys, sw.Keys) {
+			return ErrTemplateMismatch
+		}
+		if len(dw.Program) == 0 {
+			dw.Program = sw.Program
+		} else if len(sw.Program) > 0 && !bytes.Equal(dw.Program, sw.Program) {
+			return errors.WithDetail(ErrSignatureConflict, "signature program")
+		}
+
+		var h [32]byte
+		sha3pool.Sum256(h[:], dw.Program)
+		sigs, err := mergeSigs(dw.Sigs, sw.Sigs, dw.Keys, h[:])
+		if err != nil {
+			return err
+		}
+		dw.Sigs = sigs
+
+	case *RawTxSigWitness:
+		sw, ok := src.(*RawTxSigWitness)
+		if !ok || dw.Quorum != sw.Quorum || !sameKeys(dw.Keys, sw.Keys) {
+			return ErrTemplateMismatch
+		}
+
+		h := tpl.Hash(position)
+		sigs, err := mergeSigs(dw.Sigs, sw.Sigs, dw.Keys, h.Bytes())
+		if err != nil {
+			return err
+		}
+		dw.Sigs = sigs
+
+	case DataWitness:
+		sw, ok := src.(DataWitness)
+		if !ok || !bytes.Equal(dw, sw) {
+			return ErrTemplateMismatch
+		}
+
+	default:
+		return ErrBadWitnessComponent
+	}
+	return nil
+}
+
+// mergeSigs merges the signatures of keys over msg, the signatures of both dst
+// and src must be valid
+func mergeSigs(dst, src []chainjson.HexBytes, keys []keyID, msg []byte) ([]chainjson.HexBytes, error) {
+	if len(dst) > len(keys) || len(src) > len(keys) {
+		return nil, errors.WithDetail(ErrTemplateMismatch, "signature count")
+	}
+	for _, sigs := range [][]chainjson.HexBytes{dst, src} {
+		for i, sig := range sigs {
+			if len(sig) > 0 && !keys[i].verify(msg, sig) {
+				return nil, errors.WithDetailf(ErrBadMergeSignature, "key %d", i)
+			}
+		}
+	}
+
+	sigs := make([]chainjson.HexBytes, len(keys))
+	copy(sigs, dst)
+	for i, sig := range src {
+		if len(sig) == 0 {
+			continue
+		}
+		if len(sigs[i]) == 0 {
+			sigs[i] = sig
+		} else if !bytes.Equal(sigs[i], sig) {
+			return nil, errors.WithDetailf(ErrSignatureConflict, "key %d", i)
+		}
+	}
+	return sigs, nil
+}
+
+func sameKeys(a, b []keyID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].XPub != b[i].XPub || len(a[i].DerivationPath) != len(b[i].DerivationPath) {
+			return false
+		}
+		for j := range a[i].DerivationPath {
+			if !bytes.Equal(a[i].DerivationPath[j], b[i].DerivationPath[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
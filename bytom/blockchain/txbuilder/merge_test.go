@@ -0,0 +1,180 @@
+package txbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+)
+
+// newMultiSigTemplate builds a template with a 2-of-2 signature witness input
+// and a 2-of-2 raw transaction signature input
+func newMultiSigTemplate(t *testing.T) (*Template, chainkd.XPrv, chainkd.XPrv) {
+	xprv1, xpub1, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xprv2, xpub2, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txData := types.TxData{
+		Version: 1,
+		Inputs: []*types.TxInput{
+			types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 10000, 0, []byte{byte(vm.OP_TRUE)}),
+			types.NewSpendInput(nil, bc.Hash{V0: 2}, *consensus.BTMAssetID, 20000, 0, []byte{byte(vm.OP_TRUE)}),
+		},
+		Outputs: []*types.TxOutput{types.NewTxOutput(*consensus.BTMAssetID, 25000, []byte{byte(vm.OP_TRUE)})},
+	}
+
+	xpubs := []chainkd.XPub{xpub1, xpub2}
+	tpl := &Template{Transaction: types.NewTx(txData)}
+	sigInst := &SigningInstruction{Position: 0}
+	sigInst.AddWitnessKeys(xpubs, [][]byte{{1}}, 2)
+	rawSigInst := &SigningInstruction{Position: 1}
+	rawSigInst.AddRawWitnessKeys(xpubs, [][]byte{{2}}, 2)
+	tpl.SigningInstructions = []*SigningInstruction{sigInst, rawSigInst}
+	return tpl, xprv1, xprv2
+}
+
+func copyTemplate(t *testing.T, tpl *Template) *Template {
+	b, err := json.Marshal(tpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &Template{}
+	if err := json.Unmarshal(b, result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func signedTemplates(t *testing.T) (*Template, *Template) {
+	tpl, xprv1, xprv2 := newMultiSigTemplate(t)
+	tpl1, tpl2 := copyTemplate(t, tpl), copyTemplate(t, tpl)
+	if err := Sign(context.Background(), tpl1, "", testSignFunc(xprv1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Sign(context.Background(), tpl2, "", testSignFunc(xprv2)); err != nil {
+		t.Fatal(err)
+	}
+	return tpl1, tpl2
+}
+
+func TestMergeTemplates(t *testing.T) {
+	tpl1, tpl2 := signedTemplates(t)
+	for _, p := range SignProgressDetail(tpl1) {
+		if p.Signed != 1 || p.Quorum != 2 || p.Complete {
+			t.Fatalf("input %d: got progress %d/%d complete %v before merging", p.Position, p.Signed, p.Quorum, p.Complete)
+		}
+	}
+
+	if err := MergeTemplates(tpl1, tpl2); err != nil {
+		t.Fatal(err)
+	}
+	if !SignProgress(tpl1) {
+		t.Fatal("the merged template is not fully signed")
+	}
+	for _, p := range SignProgressDetail(tpl1) {
+		if p.Signed != 2 || p.Quorum != 2 || !p.Complete {
+			t.Errorf("input %d: got progress %d/%d complete %v after merging", p.Position, p.Signed, p.Quorum, p.Complete)
+		}
+	}
+
+	statuses, err := VerifySignatures(tpl1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if !status.Valid {
+			t.Errorf("input %d xpub %s: the merged signature is invalid", status.Position, status.XPub.String())
+		}
+	}
+}
+
+func TestMergeTemplatesErrors(t *testing.T) {
+	cases := []struct {
+		desc   string
+		modify func(dst, src *Template)
+		want   error
+	}{
+		{
+			desc: "different transaction",
+			modify: func(dst, src *Template) {
+				txData := src.Transaction.TxData
+				txData.Outputs = []*types.TxOutput{types.NewTxOutput(*consensus.BTMAssetID, 20000, []byte{byte(vm.OP_TRUE)})}
+				src.Transaction = types.NewTx(txData)
+			},
+			want: ErrTemplateMismatch,
+		},
+		{
+			desc: "different signature program",
+			modify: func(dst, src *Template) {
+				src.SigningInstructions[0].WitnessComponents[0].(*SignatureWitness).Program = []byte{byte(vm.OP_TRUE)}
+			},
+			want: ErrSignatureConflict,
+		},
+		{
+			desc: "garbage signature witness signature",
+			modify: func(dst, src *Template) {
+				src.SigningInstructions[0].WitnessComponents[0].(*SignatureWitness).Sigs[0] = make(chainjson.HexBytes, 64)
+			},
+			want: ErrBadMergeSignature,
+		},
+		{
+			desc: "garbage raw transaction signature",
+			modify: func(dst, src *Template) {
+				src.SigningInstructions[1].WitnessComponents[0].(*RawTxSigWitness).Sigs[0] = make(chainjson.HexBytes, 64)
+			},
+			want: ErrBadMergeSignature,
+		},
+		{
+			desc: "garbage signature in destination",
+			modify: func(dst, src *Template) {
+				dst.SigningInstructions[1].WitnessComponents[0].(*RawTxSigWitness).Sigs[1] = make(chainjson.HexBytes, 64)
+			},
+			want: ErrBadMergeSignature,
+		},
+		{
+			desc: "signature of another input",
+			modify: func(dst, src *Template) {
+				rawSigs := src.SigningInstructions[1].WitnessComponents[0].(*RawTxSigWitness).Sigs
+				src.SigningInstructions[0].WitnessComponents[0].(*SignatureWitness).Sigs[1] = rawSigs[1]
+			},
+			want: ErrBadMergeSignature,
+		},
+	}
+
+	for _, c := range cases {
+		dst, src := signedTemplates(t)
+		c.modify(dst, src)
+		if err := MergeTemplates(dst, src); errors.Root(err) != c.want {
+			t.Errorf("%s: got error %v, want %v", c.desc, err, c.want)
+		}
+	}
+}
+
+func TestMergeTemplatesKeepsHonestSignature(t *testing.T) {
+	dst, src := signedTemplates(t)
+	garbage := copyTemplate(t, dst)
+	garbage.SigningInstructions[1].WitnessComponents[0].(*RawTxSigWitness).Sigs = []chainjson.HexBytes{nil, make(chainjson.HexBytes, 64)}
+	if err := MergeTemplates(dst, garbage); errors.Root(err) != ErrBadMergeSignature {
+		t.Fatalf("got error %v, want %v", err, ErrBadMergeSignature)
+	}
+
+	if err := MergeTemplates(dst, src); err != nil {
+		t.Fatalf("the honest signature is refused: %v", err)
+	}
+	if !SignProgress(dst) {
+		t.Error("the merged template is not fully signed")
+	}
+}
@@ -4,9 +4,8 @@ import (
 	"context"
 	"encoding/json"
 
-	"log"
-
 	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
 )
 
 // signature_witness.go and needs refactoring.
@@ -37,17 +36,15 @@ func (sw *RawTxSigWitness) sign(ctx context.Context, tpl *Template, index uint32
 		for i, p := range keyID.DerivationPath {
 			path[i] = p
 		}
-		sigBytes, err := signFn(ctx, keyID.XPub, path, tpl.Hash(index).Byte32(), auth)
-		if err != nil {
-			log.Printf("err: %s, computing signature %d", err.Error(), i)
+		sigBytes, err := signFn(ctx, keyID.XPub, path, tpl.Hash(tpl.SigningInstructions[index].Position).Byte32(), auth)
+		if errors.Root(err) == ErrKeyNotOwned {
 			continue
 		}
+		if err != nil {
+			return errors.WithDetailf(err, "computing signature %d", i)
+		}
 
-		// This break is ordered to avoid signing transaction successfully only once for a multiple-sign account
-		// that consist of different keys by the same password. Exit immediately when the signature is success,
-		// it means that only one signature will be successful in the loop for this multiple-sign account.
 		sw.Sigs[i] = sigBytes
-		break
 	}
 	return nil
 }
@@ -78,3 +75,22 @@ func (sw RawTxSigWitness) MarshalJSON() ([]byte, error) {
 	}
 	return json.Marshal(obj)
 }
+
+// UnmarshalJSON convert json to struct
+func (sw *RawTxSigWitness) UnmarshalJSON(b []byte) error {
+	var obj struct {
+		Type   string               `json:"type"`
+		Quorum int                  `json:"quorum"`
+		Keys   []keyID              `json:"keys"`
+		Sigs   []chainjson.HexBytes `json:"signatures"`
+	}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	if obj.Type != "raw_tx_signature" {
+		return errors.WithDetailf(ErrBadWitnessComponent, "type %s is not raw_tx_signature", obj.Type)
+	}
+
+	sw.Quorum, sw.Keys, sw.Sigs = obj.Quorum, obj.Keys, obj.Sigs
+	return nil
+}
@@ -1,6 +1,10 @@
 package txbuilder
 
 import (
+	"math"
+
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
 	"github.com/bytom-community/wasm/bytom/protocol/vm"
 	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
 )
@@ -33,6 +37,19 @@ import (
 //   <txsighash> TXSIGHASH EQUAL
 // which commits to the transaction as-is.
 
+// errors of the constrained signature program
+var (
+	// ErrNotAllowAdditional means the template does not allow additional actions
+	ErrNotAllowAdditional = errors.New("template does not allow additional actions")
+	// ErrBadSigConstraints means the custom constraints are invalid
+	ErrBadSigConstraints = errors.New("invalid signature program constraints")
+	// ErrRawTxSigAllowAdditional means the input signed by raw transaction signature, such as the
+	// P2WPKH and P2SPMultiSig input of standard account, can not be signed with constraints
+	ErrRawTxSigAllowAdditional = errors.New("standard account input signed by raw transaction signature can not be partially signed")
+	// ErrUnusedSigConstraints means the custom constraints are given for the input without signature program to fill
+	ErrUnusedSigConstraints = errors.New("signature program constraints are not used by any input")
+)
+
 func buildSigProgram(tpl *Template, index uint32) ([]byte, error) {
 	if !tpl.AllowAdditional {
 		h := tpl.Hash(index)
@@ -42,12 +59,108 @@ func buildSigProgram(tpl *Template, index uint32) ([]byte, error) {
 
 		return builder.Build()
 	}
+	return buildConstraintProgram(defaultConstraints(tpl, index)), nil
+}
+
+// BuildConstrainedSigProgram build the signature program of the input at the
+// given position under the allow additional actions mode with the custom constraints.
+func BuildConstrainedSigProgram(tpl *Template, index uint32, c *SigConstraints) ([]byte, error) {
+	if !tpl.AllowAdditional {
+		return nil, errors.Wrap(ErrNotAllowAdditional)
+	}
+	if index >= uint32(len(tpl.Transaction.Inputs)) {
+		return nil, errors.WithDetailf(ErrBadTxInputIdx, "missing tx input %d", index)
+	}
+	if c == nil {
+		return buildConstraintProgram(defaultConstraints(tpl, index)), nil
+	}
+	if c.MaxBlockHeight >= math.MaxInt64 || c.MinBlockHeight > math.MaxInt64 || (c.MaxBlockHeight > 0 && c.MinBlockHeight > c.MaxBlockHeight) {
+		return nil, errors.WithDetailf(ErrBadSigConstraints, "block height range %d to %d", c.MinBlockHeight, c.MaxBlockHeight)
+	}
+
+	constraints := make([]constraint, 0, 2+len(tpl.Transaction.Outputs))
+	if c.SpentOutputID != nil {
+		constraints = append(constraints, outputIDConstraint(*c.SpentOutputID))
+	} else if id := spentOutputID(tpl, index); id != nil {
+		constraints = append(constraints, outputIDConstraint(*id))
+	}
+
+	if c.MinBlockHeight > 0 || c.MaxBlockHeight > 0 {
+		constraints = append(constraints, heightConstraint{Min: c.MinBlockHeight, Max: c.MaxBlockHeight})
+	}
+
+	if c.Outputs == nil {
+		constraints = append(constraints, outputConstraints(tpl)...)
+	}
+	for _, out := range c.Outputs {
+		if out.Index < 0 {
+			return nil, errors.WithDetailf(ErrBadSigConstraints, "output index %d", out.Index)
+		}
+		constraints = append(constraints, &payConstraint{
+			Index:       out.Index,
+			AssetAmount: bc.AssetAmount{AssetId: &out.AssetID, Amount: out.Amount},
+			Program:     out.ControlProgram,
+		})
+	}
+	if len(constraints) == 0 {
+		return nil, errors.Wrap(ErrEmptyProgram)
+	}
+	return buildConstraintProgram(constraints), nil
+}
+
+// ApplySigConstraints fill the signature programs of the unsigned inputs with the
+// custom constraints of their positions. The P2WPKH and P2SPMultiSig programs of
+// standard accounts check the signatures over the txsighash directly instead of a
+// signature program, so their inputs commit to the whole transaction and the
+// template including them is refused with ErrRawTxSigAllowAdditional.
+func ApplySigConstraints(tpl *Template, cs InputSigConstraints) error {
+	used := make(map[uint32]bool, len(cs))
+	for i, sigInst := range tpl.SigningInstructions {
+		for _, wc := range sigInst.WitnessComponents {
+			switch sw := wc.(type) {
+			case *SignatureWitness:
+				if len(sw.Program) > 0 {
+					continue
+				}
+
+				program, err := BuildConstrainedSigProgram(tpl, sigInst.Position, cs[sigInst.Position])
+				if err != nil {
+					return errors.WithDetailf(err, "signing instruction %d", i)
+				}
+				sw.Program = program
+				used[sigInst.Position] = true
+			case *RawTxSigWitness:
+				return errors.WithDetailf(ErrRawTxSigAllowAdditional, "input %d", sigInst.Position)
+			}
+		}
+	}
+
+	for position := range cs {
+		if !used[position] {
+			return errors.WithDetailf(ErrUnusedSigConstraints, "input %d", position)
+		}
+	}
+	return nil
+}
+
+func defaultConstraints(tpl *Template, index uint32) []constraint {
 	constraints := make([]constraint, 0, 3+len(tpl.Transaction.Outputs))
+	if id := spentOutputID(tpl, index); id != nil {
+		constraints = append(constraints, outputIDConstraint(*id))
+	}
+	return append(constraints, outputConstraints(tpl)...)
+}
+
+func spentOutputID(tpl *Template, index uint32) *bc.Hash {
 	id := tpl.Transaction.Tx.InputIDs[index]
 	if sp, err := tpl.Transaction.Tx.Spend(id); err == nil {
-		constraints = append(constraints, outputIDConstraint(*sp.SpentOutputId))
+		return sp.SpentOutputId
 	}
+	return nil
+}
 
+func outputConstraints(tpl *Template) []constraint {
+	var constraints []constraint
 	for i, out := range tpl.Transaction.Outputs {
 		c := &payConstraint{
 			Index:       i,
@@ -56,6 +169,10 @@ func buildSigProgram(tpl *Template, index uint32) ([]byte, error) {
 		}
 		constraints = append(constraints, c)
 	}
+	return constraints
+}
+
+func buildConstraintProgram(constraints []constraint) []byte {
 	var program []byte
 	for i, c := range constraints {
 		program = append(program, c.code()...)
@@ -63,5 +180,5 @@ func buildSigProgram(tpl *Template, index uint32) ([]byte, error) {
 			program = append(program, byte(vm.OP_VERIFY))
 		}
 	}
-	return program, nil
+	return program
 }
@@ -0,0 +1,99 @@
+package txbuilder
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+)
+
+func TestApplySigConstraints(t *testing.T) {
+	tpl, _ := newTestTemplate(t, true)
+	spentOutputID := bc.Hash{V0: 3}
+	cs := InputSigConstraints{1: {SpentOutputID: &spentOutputID}}
+	if err := ApplySigConstraints(tpl, cs); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sigInst := range tpl.SigningInstructions {
+		want, err := BuildConstrainedSigProgram(tpl, sigInst.Position, cs[sigInst.Position])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sigInst.WitnessComponents[0].(*SignatureWitness).Program; !bytes.Equal(got, want) {
+			t.Errorf("input %d: got program %x, want %x", sigInst.Position, got, want)
+		}
+	}
+
+	program0 := tpl.SigningInstructions[0].WitnessComponents[0].(*SignatureWitness).Program
+	program1 := tpl.SigningInstructions[1].WitnessComponents[0].(*SignatureWitness).Program
+	if bytes.Equal(program0, program1) {
+		t.Errorf("the constraints of input 1 are applied to input 0")
+	}
+}
+
+func TestApplySigConstraintsErrors(t *testing.T) {
+	rawTxSigTpl, _ := newTestTemplate(t, true)
+	_, xpub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawTxSigTpl.SigningInstructions[1].WitnessComponents = nil
+	rawTxSigTpl.SigningInstructions[1].AddRawWitnessKeys([]chainkd.XPub{xpub}, nil, 1)
+
+	unusedTpl, _ := newTestTemplate(t, true)
+
+	cases := []struct {
+		desc string
+		tpl  *Template
+		cs   InputSigConstraints
+		want error
+	}{
+		{desc: "raw transaction signature", tpl: rawTxSigTpl, want: ErrRawTxSigAllowAdditional},
+		{desc: "unused constraints", tpl: unusedTpl, cs: InputSigConstraints{2: {MaxBlockHeight: 100}}, want: ErrUnusedSigConstraints},
+	}
+
+	for _, c := range cases {
+		if err := ApplySigConstraints(c.tpl, c.cs); errors.Root(err) != c.want {
+			t.Errorf("%s: got error %v, want %v", c.desc, err, c.want)
+		}
+	}
+}
+
+func TestSignRebuildsProgram(t *testing.T) {
+	tamperedTpl, xprv := newTestTemplate(t, false)
+	tamperedTpl.SigningInstructions[0].WitnessComponents[0].(*SignatureWitness).Program = []byte{byte(vm.OP_TRUE)}
+	if err := Sign(context.Background(), tamperedTpl, "", testSignFunc(xprv)); errors.Root(err) != ErrBadSigProgram {
+		t.Errorf("tampered program: got error %v, want %v", err, ErrBadSigProgram)
+	}
+
+	constrainedTpl, xprv := newTestTemplate(t, true)
+	cs := InputSigConstraints{1: {MaxBlockHeight: 100}}
+	if err := ApplySigConstraints(constrainedTpl, cs); err != nil {
+		t.Fatal(err)
+	}
+	if err := Sign(context.Background(), constrainedTpl, "", testSignFunc(xprv)); errors.Root(err) != ErrBadSigProgram {
+		t.Errorf("constrained program without constraints: got error %v, want %v", err, ErrBadSigProgram)
+	}
+	if err := SignWithConstraints(context.Background(), constrainedTpl, cs, "", testSignFunc(xprv)); err != nil {
+		t.Errorf("constrained program: %v", err)
+	}
+
+	// the counterparty adds an output and signs, the signed programs are kept
+	txData := constrainedTpl.Transaction.TxData
+	txData.Outputs = append(txData.Outputs, types.NewTxOutput(*consensus.BTMAssetID, 1000, []byte{byte(vm.OP_TRUE)}))
+	constrainedTpl.Transaction = types.NewTx(txData)
+	otherXPrv, _, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Sign(context.Background(), constrainedTpl, "", testSignFunc(otherXPrv)); err != nil {
+		t.Errorf("signed programs of the counterparty: %v", err)
+	}
+}
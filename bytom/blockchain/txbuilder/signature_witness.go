@@ -1,11 +1,10 @@
 package txbuilder
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 
-	"log"
-
 	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
 	"github.com/bytom-community/wasm/bytom/crypto/sha3pool"
 	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
@@ -38,8 +37,13 @@ type (
 	}
 )
 
-// ErrEmptyProgram is a type of error
-var ErrEmptyProgram = errors.New("empty signature program")
+// errors of the signature witness
+var (
+	// ErrEmptyProgram is a type of error
+	ErrEmptyProgram = errors.New("empty signature program")
+	// ErrBadSigProgram means the signature program of template is not the one rebuilt from it
+	ErrBadSigProgram = errors.New("signature program mismatch the template")
+)
 
 // Sign populates sw.Sigs with as many signatures of the predicate in
 // sw.Program as it can from the overlapping set of keys in sw.Keys.
@@ -50,20 +54,33 @@ var ErrEmptyProgram = errors.New("empty signature program")
 //  - the mintime and maxtime of the transaction (if non-zero)
 //  - the outputID of the current input
 //  - the assetID, amount, control program of each output.
-func (sw *SignatureWitness) sign(ctx context.Context, tpl *Template, index uint32, auth string, signFn SignFunc) error {
+// The custom constraints c replace the inferred ones if given. A sw.Program
+// supplied by the template is only signed if it is the rebuilt one.
+func (sw *SignatureWitness) sign(ctx context.Context, tpl *Template, index uint32, c *SigConstraints, auth string, signFn SignFunc) error {
 	// Compute the predicate to sign. This is either a
 	// txsighash program if tpl.AllowAdditional is false (i.e., the tx is complete
 	// and no further changes are allowed) or a program enforcing
 	// constraints derived from the existing outputs and current input.
-	if len(sw.Program) == 0 {
+	var program []byte
+	rebuild := func() error {
+		if program != nil {
+			return nil
+		}
+
 		var err error
-		sw.Program, err = buildSigProgram(tpl, tpl.SigningInstructions[index].Position)
-		if err != nil {
+		if program, err = rebuildSigProgram(tpl, tpl.SigningInstructions[index].Position, c); err != nil {
 			return err
 		}
-		if len(sw.Program) == 0 {
+		if len(program) == 0 {
 			return ErrEmptyProgram
 		}
+		return nil
+	}
+	if len(sw.Program) == 0 {
+		if err := rebuild(); err != nil {
+			return err
+		}
+		sw.Program = program
 	}
 	if len(sw.Sigs) < len(sw.Keys) {
 		// Each key in sw.Keys may produce a signature in sw.Sigs. Make
@@ -80,21 +97,29 @@ func (sw *SignatureWitness) sign(ctx context.Context, tpl *Template, index uint3
 			// Already have a signature for this key
 			continue
 		}
+
+		// the program is only checked when there are keys to sign, so the
+		// signed programs of the counterparty are left as they are
+		if err := rebuild(); err != nil {
+			return err
+		}
+		if !bytes.Equal(sw.Program, program) {
+			return errors.WithDetailf(ErrBadSigProgram, "program %x, rebuilt %x", []byte(sw.Program), program)
+		}
+
 		path := make([][]byte, len(keyID.DerivationPath))
 		for i, p := range keyID.DerivationPath {
 			path[i] = p
 		}
 		sigBytes, err := signFn(ctx, keyID.XPub, path, h, auth)
-		if err != nil {
-			log.Printf("err: %s computing signature %d /n", err.Error(), i)
+		if errors.Root(err) == ErrKeyNotOwned {
 			continue
 		}
+		if err != nil {
+			return errors.WithDetailf(err, "computing signature %d", i)
+		}
 
-		// This break is ordered to avoid signing transaction successfully only once for a multiple-sign account
-		// that consist of different keys by the same password. Exit immediately when the signature is success,
-		// it means that only one signature will be successful in the loop for this multiple-sign account.
 		sw.Sigs[i] = sigBytes
-		break
 	}
 	return nil
 }
@@ -120,15 +145,37 @@ func (sw SignatureWitness) materialize(args *[][]byte) error {
 // MarshalJSON convert struct to json
 func (sw SignatureWitness) MarshalJSON() ([]byte, error) {
 	obj := struct {
-		Type   string               `json:"type"`
-		Quorum int                  `json:"quorum"`
-		Keys   []keyID              `json:"keys"`
-		Sigs   []chainjson.HexBytes `json:"signatures"`
+		Type    string               `json:"type"`
+		Quorum  int                  `json:"quorum"`
+		Keys    []keyID              `json:"keys"`
+		Program chainjson.HexBytes   `json:"program,omitempty"`
+		Sigs    []chainjson.HexBytes `json:"signatures"`
 	}{
-		Type:   "signature",
-		Quorum: sw.Quorum,
-		Keys:   sw.Keys,
-		Sigs:   sw.Sigs,
+		Type:    "signature",
+		Quorum:  sw.Quorum,
+		Keys:    sw.Keys,
+		Program: sw.Program,
+		Sigs:    sw.Sigs,
 	}
 	return json.Marshal(obj)
 }
+
+// UnmarshalJSON convert json to struct
+func (sw *SignatureWitness) UnmarshalJSON(b []byte) error {
+	var obj struct {
+		Type    string               `json:"type"`
+		Quorum  int                  `json:"quorum"`
+		Keys    []keyID              `json:"keys"`
+		Program chainjson.HexBytes   `json:"program"`
+		Sigs    []chainjson.HexBytes `json:"signatures"`
+	}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	if obj.Type != "signature" {
+		return errors.WithDetailf(ErrBadWitnessComponent, "type %s is not signature", obj.Type)
+	}
+
+	sw.Quorum, sw.Keys, sw.Program, sw.Sigs = obj.Quorum, obj.Keys, obj.Program, obj.Sigs
+	return nil
+}
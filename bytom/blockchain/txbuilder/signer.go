@@ -0,0 +1,102 @@
+package txbuilder
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
+// errors of the signer registry
+var (
+	// ErrDuplicateSigner means the xpub is already owned by another signer
+	ErrDuplicateSigner = errors.New("xpub already registered by another signer")
+	// ErrBadSignature means the signature made by signer is invalid
+	ErrBadSignature = errors.New("invalid signature from signer")
+)
+
+// Signer signs the hash with the key of xpub derived by the path, it may be
+// backed by the local keystore, a hardware wallet or a remote HSM.
+type Signer interface {
+	// XPubs return the xpubs of the keys owned by the signer.
+	XPubs() []chainkd.XPub
+
+	// Sign return the signature of hash made by the key of xpub derived by the path.
+	Sign(ctx context.Context, xpub chainkd.XPub, path [][]byte, hash [32]byte) ([]byte, error)
+}
+
+// SignerRegistry routes each key to the signer owning its xpub.
+type SignerRegistry struct {
+	mu      sync.RWMutex
+	signers map[chainkd.XPub]Signer
+}
+
+// NewSignerRegistry create an empty signer registry.
+func NewSignerRegistry() *SignerRegistry {
+	return &SignerRegistry{signers: make(map[chainkd.XPub]Signer)}
+}
+
+// Register add the signer for all of its xpubs, no xpub is registered if any
+// of them is owned by another signer.
+func (r *SignerRegistry) Register(s Signer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	xpubs := s.XPubs()
+	for _, xpub := range xpubs {
+		if owner, ok := r.signers[xpub]; ok && owner != s {
+			return errors.WithDetailf(ErrDuplicateSigner, "xpub %s", xpub.String())
+		}
+	}
+	for _, xpub := range xpubs {
+		r.signers[xpub] = s
+	}
+	return nil
+}
+
+// Unregister remove the signer owning the xpub with all of its xpubs.
+func (r *SignerRegistry) Unregister(xpub chainkd.XPub) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.signers[xpub]
+	if !ok {
+		return
+	}
+	for _, x := range s.XPubs() {
+		if r.signers[x] == s {
+			delete(r.signers, x)
+		}
+	}
+}
+
+// Signer return the signer owning the xpub.
+func (r *SignerRegistry) Signer(xpub chainkd.XPub) (Signer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.signers[xpub]
+	return s, ok
+}
+
+// SignFunc return the SignFunc routing each key to its signer, the key without
+// signer is not owned, and the signature is verified since the signer may be
+// out of the process.
+func (r *SignerRegistry) SignFunc() SignFunc {
+	return func(ctx context.Context, xpub chainkd.XPub, path [][]byte, hash [32]byte, _ string) ([]byte, error) {
+		s, ok := r.Signer(xpub)
+		if !ok {
+			return nil, errors.WithDetailf(ErrKeyNotOwned, "no signer for xpub %s", xpub.String())
+		}
+
+		sig, err := s.Sign(ctx, xpub, path, hash)
+		if err != nil {
+			return nil, err
+		}
+		if !xpub.Derive(path).Verify(hash[:], sig) {
+			return nil, errors.WithDetailf(ErrBadSignature, "xpub %s", xpub.String())
+		}
+		return sig, nil
+	}
+}
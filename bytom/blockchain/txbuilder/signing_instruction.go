@@ -1,8 +1,50 @@
 package txbuilder
 
+import (
+	"encoding/json"
+
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
 // AddWitnessKeys adds a SignatureWitness with the given quorum and
 // list of keys derived by applying the derivation path to each of the
 // xpubs.
+func (si *SigningInstruction) AddWitnessKeys(xpubs []chainkd.XPub, path [][]byte, quorum int) {
+	si.WitnessComponents = append(si.WitnessComponents, &SignatureWitness{
+		Quorum: quorum,
+		Keys:   keyIDs(xpubs, path),
+	})
+}
+
+// AddRawWitnessKeys adds a RawTxSigWitness with the given quorum and
+// list of keys derived by applying the derivation path to each of the
+// xpubs.
+func (si *SigningInstruction) AddRawWitnessKeys(xpubs []chainkd.XPub, path [][]byte, quorum int) {
+	si.WitnessComponents = append(si.WitnessComponents, &RawTxSigWitness{
+		Quorum: quorum,
+		Keys:   keyIDs(xpubs, path),
+	})
+}
+
+// AddDataWitness append data to the witness array
+func (si *SigningInstruction) AddDataWitness(data chainjson.HexBytes) {
+	si.WitnessComponents = append(si.WitnessComponents, DataWitness(data))
+}
+
+func keyIDs(xpubs []chainkd.XPub, path [][]byte) []keyID {
+	hexPath := make([]chainjson.HexBytes, 0, len(path))
+	for _, p := range path {
+		hexPath = append(hexPath, p)
+	}
+
+	keys := make([]keyID, 0, len(xpubs))
+	for _, xpub := range xpubs {
+		keys = append(keys, keyID{XPub: xpub, DerivationPath: hexPath})
+	}
+	return keys
+}
 
 // SigningInstruction gives directions for signing inputs in a TxTemplate.
 type SigningInstruction struct {
@@ -10,6 +52,56 @@ type SigningInstruction struct {
 	WitnessComponents []witnessComponent `json:"witness_components,omitempty"`
 }
 
+// UnmarshalJSON unmarshal SigningInstruction, the witness components are
+// decoded by their type tag
+func (si *SigningInstruction) UnmarshalJSON(b []byte) error {
+	var pre struct {
+		Position          uint32            `json:"position"`
+		WitnessComponents []json.RawMessage `json:"witness_components"`
+	}
+	if err := json.Unmarshal(b, &pre); err != nil {
+		return err
+	}
+
+	si.Position = pre.Position
+	si.WitnessComponents = make([]witnessComponent, 0, len(pre.WitnessComponents))
+	for i, wc := range pre.WitnessComponents {
+		var t struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(wc, &t); err != nil {
+			return errors.Wrapf(err, "unmarshaling error on witness component %d, input %s", i, wc)
+		}
+
+		switch t.Type {
+		case "data":
+			var dw DataWitness
+			if err := json.Unmarshal(wc, &dw); err != nil {
+				return errors.Wrapf(err, "unmarshaling error on witness component %d, type data, input %s", i, wc)
+			}
+			si.WitnessComponents = append(si.WitnessComponents, dw)
+
+		case "signature":
+			sw := &SignatureWitness{}
+			if err := json.Unmarshal(wc, sw); err != nil {
+				return errors.Wrapf(err, "unmarshaling error on witness component %d, type signature, input %s", i, wc)
+			}
+			si.WitnessComponents = append(si.WitnessComponents, sw)
+
+		case "raw_tx_signature":
+			sw := &RawTxSigWitness{}
+			if err := json.Unmarshal(wc, sw); err != nil {
+				return errors.Wrapf(err, "unmarshaling error on witness component %d, type raw_tx_signature, input %s", i, wc)
+			}
+			si.WitnessComponents = append(si.WitnessComponents, sw)
+
+		default:
+			return errors.WithDetailf(ErrBadWitnessComponent, "witness component %d has unknown type '%s'", i, t.Type)
+		}
+	}
+	return nil
+}
+
 // witnessComponent is the abstract type for the parts of a
 // SigningInstruction.  Each witnessComponent produces one or more
 // arguments for a VM program via its materialize method. Concrete
@@ -14,13 +14,29 @@ var (
 	ErrBadTxInputIdx = errors.New("unsigned tx missing input")
 )
 
-// Sign will try to sign all the witness
+// Sign will try to sign all the witness, the signature programs in the
+// template must be the default ones rebuilt from the template
 func Sign(ctx context.Context, tpl *Template, auth string, signFn SignFunc) error {
+	return SignWithConstraints(ctx, tpl, nil, auth, signFn)
+}
+
+// SignWithConstraints will try to sign all the witness, the signature programs
+// in the template must be the ones rebuilt from the template and the custom
+// constraints cs, as VerifySignatures checks them
+func SignWithConstraints(ctx context.Context, tpl *Template, cs InputSigConstraints, auth string, signFn SignFunc) error {
+	if tpl.Transaction == nil {
+		return errors.Wrap(ErrMissingRawTx)
+	}
+
 	for i, sigInst := range tpl.SigningInstructions {
+		if sigInst.Position >= uint32(len(tpl.Transaction.Inputs)) {
+			return errors.WithDetailf(ErrBadTxInputIdx, "signing instruction %d references missing tx input %d", i, sigInst.Position)
+		}
+
 		for j, wc := range sigInst.WitnessComponents {
 			switch sw := wc.(type) {
 			case *SignatureWitness:
-				err := sw.sign(ctx, tpl, uint32(i), auth, signFn)
+				err := sw.sign(ctx, tpl, uint32(i), cs[sigInst.Position], auth, signFn)
 				if err != nil {
 					return errors.WithDetailf(err, "adding signature(s) to signature witness component %d of input %d", j, i)
 				}
@@ -0,0 +1,100 @@
+package txbuilder
+
+import (
+	"bytes"
+
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/crypto/sha3pool"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
+// SignatureStatus is the verification result of the signature of a key in template
+type SignatureStatus struct {
+	Position       uint32               `json:"position"`
+	XPub           chainkd.XPub         `json:"xpub"`
+	DerivationPath []chainjson.HexBytes `json:"derivation_path"`
+	Signed         bool                 `json:"signed"`
+
+	// SignatureValid means the signature is made by the key over the signed
+	// program or sighash, whatever the program is.
+	SignatureValid bool `json:"signature_valid"`
+
+	// Valid means the signature is valid and the signed program is the one
+	// rebuilt from the template and the constraints.
+	Valid bool `json:"valid"`
+}
+
+// VerifySignatures checks each signature of the template against the key derived
+// from its xpub and derivation path. The signature of RawTxSigWitness must sign the
+// sighash of its input, and the signature of SignatureWitness must sign the hash of
+// its program, which must be rebuilt from the template: the sighash program when
+// additional actions are not allowed, otherwise the constraint program of the input
+// in cs, or of the spent output and the outputs of transaction if it is missing.
+func VerifySignatures(tpl *Template, cs InputSigConstraints) ([]*SignatureStatus, error) {
+	if tpl.Transaction == nil {
+		return nil, errors.Wrap(ErrMissingRawTx)
+	}
+
+	var statuses []*SignatureStatus
+	for i, sigInst := range tpl.SigningInstructions {
+		if sigInst.Position >= uint32(len(tpl.Transaction.Inputs)) {
+			return nil, errors.WithDetailf(ErrBadTxInputIdx, "signing instruction %d references missing tx input %d", i, sigInst.Position)
+		}
+
+		for _, wc := range sigInst.WitnessComponents {
+			switch sw := wc.(type) {
+			case *SignatureWitness:
+				program, err := rebuildSigProgram(tpl, sigInst.Position, cs[sigInst.Position])
+				if err != nil {
+					return nil, err
+				}
+
+				var h [32]byte
+				sha3pool.Sum256(h[:], sw.Program)
+				validProgram := len(sw.Program) > 0 && bytes.Equal(program, sw.Program)
+				statuses = append(statuses, verifyKeys(sigInst.Position, sw.Keys, sw.Sigs, h[:], validProgram)...)
+
+			case *RawTxSigWitness:
+				h := tpl.Hash(sigInst.Position)
+				statuses = append(statuses, verifyKeys(sigInst.Position, sw.Keys, sw.Sigs, h.Bytes(), true)...)
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// rebuildSigProgram rebuild the signature program the input at position must sign
+func rebuildSigProgram(tpl *Template, position uint32, c *SigConstraints) ([]byte, error) {
+	if !tpl.AllowAdditional {
+		return buildSigProgram(tpl, position)
+	}
+	return BuildConstrainedSigProgram(tpl, position, c)
+}
+
+func verifyKeys(position uint32, keys []keyID, sigs []chainjson.HexBytes, msg []byte, validMsg bool) []*SignatureStatus {
+	statuses := make([]*SignatureStatus, 0, len(keys))
+	for i, key := range keys {
+		status := &SignatureStatus{
+			Position:       position,
+			XPub:           key.XPub,
+			DerivationPath: key.DerivationPath,
+		}
+		if i < len(sigs) && len(sigs[i]) > 0 {
+			status.Signed = true
+			status.SignatureValid = key.verify(msg, sigs[i])
+			status.Valid = validMsg && status.SignatureValid
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// verify checks the signature of msg against the key derived from the xpub by the derivation path
+func (k keyID) verify(msg, sig []byte) bool {
+	path := make([][]byte, len(k.DerivationPath))
+	for i, p := range k.DerivationPath {
+		path[i] = p
+	}
+	return k.XPub.Derive(path).Verify(msg, sig)
+}
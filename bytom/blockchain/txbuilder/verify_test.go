@@ -0,0 +1,132 @@
+package txbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/crypto/sha3pool"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+)
+
+func newTestTemplate(t *testing.T, allowAdditional bool) (*Template, chainkd.XPrv) {
+	xprv, xpub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txData := types.TxData{
+		Version: 1,
+		Inputs: []*types.TxInput{
+			types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 10000, 0, []byte{byte(vm.OP_TRUE)}),
+			types.NewSpendInput(nil, bc.Hash{V0: 2}, *consensus.BTMAssetID, 20000, 0, []byte{byte(vm.OP_TRUE)}),
+		},
+		Outputs: []*types.TxOutput{types.NewTxOutput(*consensus.BTMAssetID, 25000, []byte{byte(vm.OP_TRUE)})},
+	}
+
+	tpl := &Template{Transaction: types.NewTx(txData), AllowAdditional: allowAdditional}
+	for i := range txData.Inputs {
+		sigInst := &SigningInstruction{Position: uint32(i)}
+		sigInst.AddWitnessKeys([]chainkd.XPub{xpub}, nil, 1)
+		tpl.SigningInstructions = append(tpl.SigningInstructions, sigInst)
+	}
+	return tpl, xprv
+}
+
+func testSignFunc(xprv chainkd.XPrv) SignFunc {
+	return func(_ context.Context, xpub chainkd.XPub, path [][]byte, data [32]byte, _ string) ([]byte, error) {
+		if xpub != xprv.XPub() {
+			return nil, errors.Wrap(ErrKeyNotOwned)
+		}
+		return xprv.Derive(path).Sign(data[:]), nil
+	}
+}
+
+// signProgram signs the programs of the signature witnesses whatever they are
+func signProgram(tpl *Template, xprv chainkd.XPrv) {
+	for _, sigInst := range tpl.SigningInstructions {
+		for _, wc := range sigInst.WitnessComponents {
+			sw, ok := wc.(*SignatureWitness)
+			if !ok {
+				continue
+			}
+
+			var h [32]byte
+			sha3pool.Sum256(h[:], sw.Program)
+			sw.Sigs = make([]chainjson.HexBytes, len(sw.Keys))
+			for i, key := range sw.Keys {
+				path := make([][]byte, len(key.DerivationPath))
+				for j, p := range key.DerivationPath {
+					path[j] = p
+				}
+				sw.Sigs[i] = xprv.Derive(path).Sign(h[:])
+			}
+		}
+	}
+}
+
+func TestVerifySignatures(t *testing.T) {
+	cases := []struct {
+		desc            string
+		allowAdditional bool
+		program         []byte
+		constraints     InputSigConstraints
+		wantValid       bool
+	}{
+		{desc: "txsighash program", wantValid: true},
+		{desc: "default constraint program", allowAdditional: true, wantValid: true},
+		{desc: "arbitrary program", allowAdditional: true, program: []byte{byte(vm.OP_TRUE)}, wantValid: false},
+		{desc: "arbitrary program without allow additional", program: []byte{byte(vm.OP_TRUE)}, wantValid: false},
+		{
+			desc:            "custom constraint program",
+			allowAdditional: true,
+			constraints:     InputSigConstraints{1: {MaxBlockHeight: 100}},
+			wantValid:       true,
+		},
+	}
+
+	for _, c := range cases {
+		tpl, xprv := newTestTemplate(t, c.allowAdditional)
+		for _, sigInst := range tpl.SigningInstructions {
+			sw := sigInst.WitnessComponents[0].(*SignatureWitness)
+			sw.Program = c.program
+		}
+		if c.constraints != nil {
+			if err := ApplySigConstraints(tpl, c.constraints); err != nil {
+				t.Fatalf("%s: %v", c.desc, err)
+			}
+		}
+		err := SignWithConstraints(context.Background(), tpl, c.constraints, "", testSignFunc(xprv))
+		if c.program != nil {
+			// the arbitrary program is refused to sign, it is signed by the
+			// key directly to be verified
+			if errors.Root(err) != ErrBadSigProgram {
+				t.Fatalf("%s: got error %v, want %v", c.desc, err, ErrBadSigProgram)
+			}
+			signProgram(tpl, xprv)
+		} else if err != nil {
+			t.Fatalf("%s: %v", c.desc, err)
+		}
+
+		statuses, err := VerifySignatures(tpl, c.constraints)
+		if err != nil {
+			t.Fatalf("%s: %v", c.desc, err)
+		}
+		if len(statuses) != len(tpl.SigningInstructions) {
+			t.Fatalf("%s: got %d statuses, want %d", c.desc, len(statuses), len(tpl.SigningInstructions))
+		}
+		for _, status := range statuses {
+			if !status.Signed || !status.SignatureValid {
+				t.Errorf("%s: input %d signed %v signature valid %v", c.desc, status.Position, status.Signed, status.SignatureValid)
+			}
+			if status.Valid != c.wantValid {
+				t.Errorf("%s: input %d got valid %v, want %v", c.desc, status.Position, status.Valid, c.wantValid)
+			}
+		}
+	}
+}
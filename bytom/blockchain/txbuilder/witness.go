@@ -10,8 +10,14 @@ import (
 
 // SignFunc is the function passed into Sign that produces
 // a signature for a given xpub, derivation path, and hash.
+// It returns ErrKeyNotOwned for the key it doesn't own, which
+// is left unsigned, the other errors abort the signing.
 type SignFunc func(context.Context, chainkd.XPub, [][]byte, [32]byte, string) ([]byte, error)
 
+// ErrKeyNotOwned means the SignFunc doesn't own the key, so the signature
+// of the key is left to the other cosigners
+var ErrKeyNotOwned = errors.New("key is not owned by the signer")
+
 // MaterializeWitnesses takes a filled in Template and "materializes"
 // each witness component, turning it into a vector of arguments for
 // the tx's input witness, creating a fully-signed transaction.
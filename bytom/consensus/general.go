@@ -10,6 +10,9 @@ import (
 //consensus variables
 const (
 	BTMAlias = "BTM"
+
+	PayToWitnessPubKeyHashDataSize = 20
+	PayToWitnessScriptHashDataSize = 32
 )
 
 // BTMAssetID is BTM's asset id, the soul asset of Bytom
@@ -47,6 +50,13 @@ type Params struct {
 // ActiveNetParams is ...
 var ActiveNetParams = MainNetParams
 
+// NetParams is the correspondence between chain_id and Params
+var NetParams = map[string]Params{
+	"mainnet": MainNetParams,
+	"testnet": TestNetParams,
+	"solonet": SoloNetParams,
+}
+
 // MainNetParams is the config for production
 var MainNetParams = Params{
 	Name:            "main",
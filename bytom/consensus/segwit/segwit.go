@@ -0,0 +1,136 @@
+package segwit
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto/sha3pool"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+)
+
+// IsP2WScript is used to determine whether it is a P2WScript or not
+func IsP2WScript(prog []byte) bool {
+	return IsP2WPKHScript(prog) || IsP2WSHScript(prog) || IsStraightforward(prog)
+}
+
+// IsStraightforward is used to determine whether it is a Straightforward script or not
+func IsStraightforward(prog []byte) bool {
+	insts, err := vm.ParseProgram(prog)
+	if err != nil {
+		return false
+	}
+	if len(insts) != 1 {
+		return false
+	}
+	return insts[0].Op == vm.OP_TRUE || insts[0].Op == vm.OP_FAIL
+}
+
+// IsP2WPKHScript is used to determine whether it is a P2WPKH script or not
+func IsP2WPKHScript(prog []byte) bool {
+	insts, err := vm.ParseProgram(prog)
+	if err != nil {
+		return false
+	}
+	if len(insts) != 2 {
+		return false
+	}
+	if insts[0].Op > vm.OP_16 {
+		return false
+	}
+	return insts[1].Op == vm.OP_DATA_20 && len(insts[1].Data) == consensus.PayToWitnessPubKeyHashDataSize
+}
+
+// IsP2WSHScript is used to determine whether it is a P2WSH script or not
+func IsP2WSHScript(prog []byte) bool {
+	insts, err := vm.ParseProgram(prog)
+	if err != nil {
+		return false
+	}
+	if len(insts) != 2 {
+		return false
+	}
+	if insts[0].Op > vm.OP_16 {
+		return false
+	}
+	return insts[1].Op == vm.OP_DATA_32 && len(insts[1].Data) == consensus.PayToWitnessScriptHashDataSize
+}
+
+// ConvertP2PKHSigProgram convert standard P2WPKH program into P2PKH program
+func ConvertP2PKHSigProgram(prog []byte) ([]byte, error) {
+	insts, err := vm.ParseProgram(prog)
+	if err != nil {
+		return nil, err
+	}
+	if insts[0].Op == vm.OP_0 {
+		return vmutil.P2PKHSigProgram(insts[1].Data)
+	}
+	return nil, errors.New("unknow P2PKH version number")
+}
+
+// ConvertP2SHProgram convert standard P2WSH program into P2SH program
+func ConvertP2SHProgram(prog []byte) ([]byte, error) {
+	insts, err := vm.ParseProgram(prog)
+	if err != nil {
+		return nil, err
+	}
+	if insts[0].Op == vm.OP_0 {
+		return vmutil.P2SHProgram(insts[1].Data)
+	}
+	return nil, errors.New("unknow P2SHP version number")
+}
+
+// GetHashFromStandardProg get hash from standard program
+func GetHashFromStandardProg(prog []byte) ([]byte, error) {
+	insts, err := vm.ParseProgram(prog)
+	if err != nil {
+		return nil, err
+	}
+	if len(insts) < 2 {
+		return nil, errors.New("invalid standard program")
+	}
+
+	return insts[1].Data, nil
+}
+
+// ErrBadP2WSHWitness is returned when the witness doesn't reveal the program
+// of the P2WSH script hash
+var ErrBadP2WSHWitness = errors.New("bad P2WSH witness")
+
+// P2WSHWitness returns the witness arguments spending the P2WSH program of
+// the program, the program is appended after the clause arguments and is
+// revealed at spend time
+func P2WSHWitness(program []byte, args [][]byte) [][]byte {
+	witness := make([][]byte, 0, len(args)+1)
+	witness = append(witness, args...)
+	return append(witness, program)
+}
+
+// DecodeP2WSHWitness returns the program revealed by the witness spending the
+// standard P2WSH program and the clause arguments, the script hash of the
+// program must match the P2WSH program
+func DecodeP2WSHWitness(prog []byte, witness [][]byte) ([]byte, [][]byte, error) {
+	if !IsP2WSHScript(prog) {
+		return nil, nil, errors.New("invalid P2WSH program")
+	}
+
+	insts, err := vm.ParseProgram(prog)
+	if err != nil {
+		return nil, nil, err
+	}
+	if insts[0].Op != vm.OP_0 {
+		return nil, nil, errors.New("unknow P2WSH version number")
+	}
+	if len(witness) == 0 {
+		return nil, nil, ErrBadP2WSHWitness
+	}
+
+	program := witness[len(witness)-1]
+	var scriptHash [32]byte
+	sha3pool.Sum256(scriptHash[:], program)
+	if !bytes.Equal(scriptHash[:], insts[1].Data) {
+		return nil, nil, ErrBadP2WSHWitness
+	}
+	return program, witness[:len(witness)-1], nil
+}
@@ -0,0 +1,870 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/bytom-community/wasm/bytom/math/checked"
+)
+
+// the kinds of warning of program analysis
+const (
+	WarnUnreachable    = "unreachable"
+	WarnBadJump        = "bad_jump"
+	WarnStackUnderflow = "stack_underflow"
+	WarnNeverSucceeds  = "never_succeeds"
+	WarnNoSignature    = "no_signature"
+	WarnGas            = "gas"
+	WarnExpansion      = "expansion"
+	WarnSegwit         = "segwit"
+	WarnIncomplete     = "incomplete"
+)
+
+const (
+	// maxAnalyzeSteps is the max number of instructions run by the analysis
+	// on all the paths, including the paths of predicates
+	maxAnalyzeSteps = 100000
+
+	// maxAnalyzeDepth is the max depth of predicates analyzed
+	maxAnalyzeDepth = 8
+)
+
+// Warning is a problem of program found by AnalyzeProgram.
+type Warning struct {
+	// Offset is the byte offset of the instruction in program, the problem
+	// found in a predicate is at the offset of its CHECKPREDICATE.
+	Offset  uint32
+	Kind    string
+	Message string
+}
+
+// Analysis is the result of AnalyzeProgram.
+type Analysis struct {
+	Warnings []*Warning
+
+	// Arguments is the max number of witness arguments consumed by the
+	// program, it is only counted when the number of arguments is unknown.
+	Arguments int
+
+	// Gas is the worst-case gas of the program on the paths which can
+	// succeed, the costs depending on the size of runtime values are counted
+	// by their minimums. GasUnbounded is set when the gas can't be bounded,
+	// which is caused by loops or the predicates can't be analyzed.
+	Gas          int64
+	GasUnbounded bool
+}
+
+// absValue is the abstract value on stack, data is only valid if known.
+// The value pushed by DEPTH of open stack is the depth of the values under
+// it, which are recorded by depthOf and below.
+type absValue struct {
+	known bool
+	data  []byte
+
+	isDepth bool
+	depthOf int
+	below   int
+
+	// guard tells the value is the result of CHECKSIG, CHECKMULTISIG,
+	// CHECKOUTPUT or a guarded predicate
+	guard bool
+}
+
+func (v absValue) equal(o absValue) bool {
+	return v.known == o.known && bytes.Equal(v.data, o.data) && v.isDepth == o.isDepth && v.depthOf == o.depthOf && v.below == o.below && v.guard == o.guard
+}
+
+// absState is the abstract state of VM on a path of program
+type absState struct {
+	pc    uint32
+	last  uint32
+	stack []absValue
+	alt   []absValue
+
+	// open tells there are unknown values under stack, which are the
+	// witness arguments if below counts them
+	open  bool
+	below int
+
+	// imprecise tells stack can't be tracked after an instruction whose
+	// stack effect is unknown, hiddenGuard tells the values no longer
+	// tracked may include a guard value
+	imprecise   bool
+	hiddenGuard bool
+
+	// guarded tells a guard value has been verified on the path
+	gas     int64
+	guarded bool
+
+	// visited is the state when the path visited each pc last time
+	visited map[uint32]*absState
+}
+
+func (s *absState) fork() *absState {
+	f := *s
+	f.stack = append([]absValue{}, s.stack...)
+	f.alt = append([]absValue{}, s.alt...)
+	f.visited = make(map[uint32]*absState, len(s.visited))
+	for pc, v := range s.visited {
+		f.visited[pc] = v
+	}
+	return &f
+}
+
+// snapshot returns the copy of the stacks of state to compare with when
+// the path loops back
+func (s *absState) snapshot() *absState {
+	return &absState{
+		stack:       append([]absValue{}, s.stack...),
+		alt:         append([]absValue{}, s.alt...),
+		open:        s.open,
+		below:       s.below,
+		imprecise:   s.imprecise,
+		hiddenGuard: s.hiddenGuard,
+	}
+}
+
+// widen joins the state with prev, the state of the last visit of the
+// same pc, the values differing between them become unknown and the stack
+// of different shapes becomes untracked. It returns false if the state is
+// covered by prev, so the loop adds nothing new to the path.
+func (s *absState) widen(prev *absState) bool {
+	changed := s.imprecise != prev.imprecise || s.hiddenGuard != prev.hiddenGuard
+	if len(s.stack) != len(prev.stack) || s.open != prev.open || (s.below != prev.below && !s.imprecise) {
+		unknownStack(s, false)
+		for _, v := range prev.stack {
+			s.hiddenGuard = s.hiddenGuard || v.guard
+		}
+		s.hiddenGuard = s.hiddenGuard || prev.hiddenGuard
+		return len(prev.stack) > 0 || !prev.open || !prev.imprecise || s.hiddenGuard != prev.hiddenGuard
+	}
+
+	s.imprecise = s.imprecise || prev.imprecise
+	s.hiddenGuard = s.hiddenGuard || prev.hiddenGuard
+	for i, v := range s.stack {
+		if !v.equal(prev.stack[i]) {
+			s.stack[i] = absValue{guard: v.guard && prev.stack[i].guard}
+			changed = changed || !s.stack[i].equal(prev.stack[i])
+		}
+	}
+
+	// the alt stack is rarely used in loops, it is only joined when its
+	// size doesn't change
+	if len(s.alt) != len(prev.alt) {
+		return true
+	}
+	for i, v := range s.alt {
+		if !v.equal(prev.alt[i]) {
+			s.alt[i] = absValue{guard: v.guard && prev.alt[i].guard}
+			changed = changed || !s.alt[i].equal(prev.alt[i])
+		}
+	}
+	return changed
+}
+
+// analyzer runs all the paths of a program, the predicates run by
+// CHECKPREDICATE are analyzed by the child analyzers
+type analyzer struct {
+	program []byte
+	depth   int
+	steps   *int
+	report  func(offset uint32, kind, message string)
+	reached map[uint32]bool
+
+	// the results of the paths which can succeed
+	succeeds  bool
+	unguarded []uint32
+	arguments int
+	imprecise bool
+	gas       int64
+	unbounded bool
+}
+
+// AnalyzeProgram checks the program statically on every path, the program
+// is run with nargs witness arguments, or unknown arguments if nargs is
+// negative. The worst-case gas is checked against gasLimit if it is
+// positive.
+//
+// The results of CHECKSIG, CHECKMULTISIG, CHECKOUTPUT and the guarded
+// predicates are tracked on stack, a path is taken as guarded by the
+// signature only if such a result is consumed by VERIFY, jumped on by
+// JUMPIF, or left on the top of stack at the end. A loop is run until its
+// state reaches a fixed point, and it can succeed only by the exits from it.
+func AnalyzeProgram(program []byte, nargs int, gasLimit int64) (*Analysis, error) {
+	insts, err := ParseProgram(program)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := &Analysis{}
+	warned := make(map[string]bool)
+	report := func(offset uint32, kind, message string) {
+		key := fmt.Sprintf("%d %s %s", offset, kind, message)
+		if !warned[key] {
+			warned[key] = true
+			analysis.Warnings = append(analysis.Warnings, &Warning{Offset: offset, Kind: kind, Message: message})
+		}
+	}
+
+	if len(insts) == 2 && insts[0].Op <= OP_16 && (insts[1].Op == OP_DATA_20 || insts[1].Op == OP_DATA_32) {
+		report(0, WarnSegwit, "segwit program is expanded by validation, analyze the witness program instead")
+		return analysis, nil
+	}
+
+	init := &absState{visited: make(map[uint32]*absState)}
+	if nargs < 0 {
+		init.open = true
+	} else {
+		init.stack = make([]absValue, nargs)
+	}
+
+	steps := 0
+	a := &analyzer{program: program, steps: &steps, report: report, reached: make(map[uint32]bool)}
+	if !a.run(insts, init) {
+		report(0, WarnIncomplete, fmt.Sprintf("analysis stopped after %d steps", maxAnalyzeSteps))
+	} else if !a.succeeds {
+		report(0, WarnNeverSucceeds, "program can never succeed, the value locked by it is lost")
+	}
+	for _, offset := range a.unguarded {
+		report(offset, WarnNoSignature, "program succeeds without checking signature or output, anyone can spend it")
+	}
+
+	analysis.Gas, analysis.GasUnbounded = a.gas, a.unbounded
+	if nargs < 0 && !a.imprecise {
+		analysis.Arguments = a.arguments
+	}
+
+	switch {
+	case gasLimit <= 0:
+	case a.unbounded:
+		report(0, WarnGas, fmt.Sprintf("worst-case gas is unbounded, the gas limit %d may run out", gasLimit))
+	case a.gas > gasLimit:
+		report(0, WarnGas, fmt.Sprintf("worst-case gas %d exceeds the gas limit %d", a.gas, gasLimit))
+	}
+
+	sort.SliceStable(analysis.Warnings, func(i, j int) bool {
+		return analysis.Warnings[i].Offset < analysis.Warnings[j].Offset
+	})
+	return analysis, nil
+}
+
+// run checks the instructions and runs all the paths from the state, false
+// is returned if the analysis stops before all the paths are run
+func (a *analyzer) run(insts []Instruction, init *absState) bool {
+	offsets := make(map[uint32]bool)
+	for pc, i := uint32(0), 0; i < len(insts); pc, i = pc+insts[i].Len, i+1 {
+		offsets[pc] = true
+	}
+	for pc, i := uint32(0), 0; i < len(insts); pc, i = pc+insts[i].Len, i+1 {
+		a.checkInst(pc, insts[i], offsets)
+	}
+
+	if !a.explore(init) {
+		return false
+	}
+	a.checkReachable(insts)
+	return true
+}
+
+func (a *analyzer) checkInst(pc uint32, inst Instruction, offsets map[uint32]bool) {
+	if isExpansion[inst.Op] {
+		a.report(pc, WarnExpansion, fmt.Sprintf("expansion opcode %s is disallowed in transactions of version 1", inst.Op))
+	}
+	if inst.Op != OP_JUMP && inst.Op != OP_JUMPIF {
+		return
+	}
+
+	switch target := binary.LittleEndian.Uint32(inst.Data); {
+	case target > uint32(len(a.program)):
+		a.report(pc, WarnBadJump, fmt.Sprintf("jump target %d is beyond the program end %d", target, len(a.program)))
+	case target < uint32(len(a.program)) && !offsets[target]:
+		a.report(pc, WarnBadJump, fmt.Sprintf("jump target %d is in the middle of an instruction", target))
+	case target <= pc:
+		a.report(pc, WarnGas, fmt.Sprintf("backward jump to %d may loop until the gas runs out", target))
+	}
+}
+
+// checkReachable warns the runs of instructions never reached
+func (a *analyzer) checkReachable(insts []Instruction) {
+	start, size := uint32(0), uint32(0)
+	for pc, i := uint32(0), 0; i <= len(insts); i++ {
+		if i < len(insts) && !a.reached[pc] {
+			if size == 0 {
+				start = pc
+			}
+			size += insts[i].Len
+		} else if size > 0 {
+			a.report(start, WarnUnreachable, fmt.Sprintf("%d bytes of code are unreachable", size))
+			size = 0
+		}
+		if i < len(insts) {
+			pc += insts[i].Len
+		}
+	}
+}
+
+func (a *analyzer) explore(init *absState) bool {
+	states := []*absState{init}
+	for len(states) > 0 {
+		s := states[len(states)-1]
+		states = states[:len(states)-1]
+
+		for {
+			if *a.steps++; *a.steps > maxAnalyzeSteps {
+				return false
+			}
+
+			next, forked, ok := a.step(s)
+			if forked != nil {
+				states = append(states, forked)
+			}
+			if !ok {
+				break
+			}
+			s = next
+		}
+	}
+	return true
+}
+
+// step runs the instruction at pc of the state, ok is false when the path
+// ends, forked is the state of another path branched by the instruction
+func (a *analyzer) step(s *absState) (next, forked *absState, ok bool) {
+	if s.pc >= uint32(len(a.program)) {
+		a.end(s)
+		return nil, nil, false
+	}
+	if prev := s.visited[s.pc]; prev != nil {
+		// the loop may run until the gas runs out, it goes on only if the
+		// widened state is new, and it ends on the exits forked in it
+		a.unbounded = true
+		if !s.widen(prev) {
+			return nil, nil, false
+		}
+	}
+	s.visited[s.pc] = s.snapshot()
+	a.reached[s.pc] = true
+
+	inst, err := ParseOp(a.program, s.pc)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	pc := s.pc
+	s.last, s.pc = pc, pc+inst.Len
+	s.gas = a.addGas(s.gas, staticCost(inst))
+
+	if isExpansion[inst.Op] {
+		return s, nil, true
+	}
+
+	if inst.IsPushdata() {
+		data := inst.Data
+		if inst.Op == OP_1NEGATE {
+			data = Int64Bytes(-1)
+		}
+		s.stack = append(s.stack, absValue{known: true, data: data})
+		s.gas = a.addGas(s.gas, 8+int64(len(data)))
+		return s, nil, true
+	}
+
+	if perm, ok := permutations[inst.Op]; ok {
+		if !a.need(s, pc, perm.n) {
+			return nil, nil, false
+		}
+		top := append([]absValue{}, s.stack[len(s.stack)-perm.n:]...)
+		s.stack = s.stack[:len(s.stack)-perm.n]
+		for _, i := range perm.out {
+			s.stack = append(s.stack, top[i])
+		}
+		return s, nil, true
+	}
+
+	switch inst.Op {
+	case OP_JUMP:
+		s.pc = binary.LittleEndian.Uint32(inst.Data)
+
+	case OP_JUMPIF:
+		cond, ok := a.pop(s, pc)
+		if !ok {
+			return nil, nil, false
+		}
+		target := binary.LittleEndian.Uint32(inst.Data)
+		switch {
+		case cond.known && AsBool(cond.data):
+			s.pc = target
+		case !cond.known:
+			// only the jumping path has the guard value true
+			forked = s.fork()
+			forked.pc, forked.guarded = target, forked.guarded || cond.guard
+		}
+
+	case OP_VERIFY:
+		cond, ok := a.pop(s, pc)
+		if !ok || (cond.known && !AsBool(cond.data)) {
+			return nil, nil, false
+		}
+		s.guarded = s.guarded || cond.guard
+
+	case OP_FAIL:
+		return nil, nil, false
+
+	case OP_TOALTSTACK:
+		v, ok := a.pop(s, pc)
+		if !ok {
+			return nil, nil, false
+		}
+		s.alt = append(s.alt, v)
+
+	case OP_FROMALTSTACK:
+		if len(s.alt) == 0 {
+			a.report(pc, WarnStackUnderflow, "alt stack underflow")
+			return nil, nil, false
+		}
+		s.stack = append(s.stack, s.alt[len(s.alt)-1])
+		s.alt = s.alt[:len(s.alt)-1]
+
+	case OP_IFDUP:
+		if !a.need(s, pc, 1) {
+			return nil, nil, false
+		}
+		top := s.stack[len(s.stack)-1]
+		switch {
+		case top.known && AsBool(top.data):
+			s.stack = append(s.stack, top)
+		case !top.known:
+			forked = s.fork()
+			forked.stack = append(forked.stack, top)
+		}
+
+	case OP_DEPTH:
+		depth := absValue{}
+		switch {
+		case s.imprecise:
+		case s.open:
+			depth = absValue{isDepth: true, depthOf: len(s.stack), below: s.below}
+		default:
+			depth = absValue{known: true, data: Int64Bytes(int64(len(s.stack)))}
+		}
+		s.stack = append(s.stack, depth)
+
+	case OP_SIZE:
+		if !a.need(s, pc, 1) {
+			return nil, nil, false
+		}
+		size := absValue{}
+		if top := s.stack[len(s.stack)-1]; top.known {
+			size = absValue{known: true, data: Int64Bytes(int64(len(top.data)))}
+		}
+		s.stack = append(s.stack, size)
+
+	case OP_PICK, OP_ROLL:
+		n, ok := a.pop(s, pc)
+		if !ok {
+			return nil, nil, false
+		}
+		depth, err := AsInt64(n.data)
+		if !n.known || err != nil || depth < 0 || depth > int64(len(a.program)) {
+			unknownStack(s, inst.Op == OP_PICK)
+			break
+		}
+		if !a.need(s, pc, int(depth)+1) {
+			return nil, nil, false
+		}
+		i := len(s.stack) - 1 - int(depth)
+		v := s.stack[i]
+		if inst.Op == OP_ROLL {
+			s.stack = append(s.stack[:i], s.stack[i+1:]...)
+		}
+		s.stack = append(s.stack, v)
+
+	case OP_CHECKMULTISIG:
+		if !a.checkMultiSig(s, pc) {
+			return nil, nil, false
+		}
+
+	case OP_CHECKPREDICATE:
+		if !a.checkPredicate(s, pc) {
+			return nil, nil, false
+		}
+
+	default:
+		effect, ok := stackEffects[inst.Op]
+		if !ok {
+			return nil, nil, false
+		}
+		if !a.need(s, pc, effect.pops) {
+			return nil, nil, false
+		}
+
+		// the conjunction with a guard value is guarded too
+		result := absValue{guard: inst.Op == OP_CHECKSIG || inst.Op == OP_CHECKOUTPUT}
+		if inst.Op == OP_BOOLAND {
+			result.guard = s.stack[len(s.stack)-1].guard || s.stack[len(s.stack)-2].guard
+		}
+		s.stack = s.stack[:len(s.stack)-effect.pops]
+		for i := 0; i < effect.pushes; i++ {
+			s.stack = append(s.stack, result)
+		}
+	}
+	return s, forked, true
+}
+
+// end records the state at the end of program, the program succeeds if
+// the top of stack is true
+func (a *analyzer) end(s *absState) {
+	if len(s.stack) == 0 && !s.open {
+		return
+	}
+	if n := len(s.stack); n > 0 && s.stack[n-1].known && !AsBool(s.stack[n-1].data) {
+		return
+	}
+
+	a.succeeds = true
+	if s.imprecise {
+		a.imprecise = true
+	} else {
+		below := s.below
+		if s.open && len(s.stack) == 0 {
+			// the top of stack is the next witness argument
+			below++
+		}
+		if below > a.arguments {
+			a.arguments = below
+		}
+	}
+	if s.gas > a.gas {
+		a.gas = s.gas
+	}
+
+	guarded := s.guarded
+	if n := len(s.stack); n > 0 {
+		guarded = guarded || s.stack[n-1].guard
+	} else {
+		guarded = guarded || s.hiddenGuard
+	}
+	if !guarded {
+		a.unguarded = append(a.unguarded, s.last)
+	}
+}
+
+// need makes sure there are n values on stack, the unknown values are
+// added under stack if it is open
+func (a *analyzer) need(s *absState, pc uint32, n int) bool {
+	if len(s.stack) >= n {
+		return true
+	}
+	if !s.open {
+		a.report(pc, WarnStackUnderflow, fmt.Sprintf("stack underflow, %d values are needed but %d on stack", n, len(s.stack)))
+		return false
+	}
+
+	missing := n - len(s.stack)
+	hidden := make([]absValue, missing)
+	for i := range hidden {
+		hidden[i].guard = s.hiddenGuard
+	}
+	s.stack = append(hidden, s.stack...)
+	s.below += missing
+	return true
+}
+
+func (a *analyzer) pop(s *absState, pc uint32) (absValue, bool) {
+	if !a.need(s, pc, 1) {
+		return absValue{}, false
+	}
+	v := s.stack[len(s.stack)-1]
+	s.stack = s.stack[:len(s.stack)-1]
+	return v, true
+}
+
+// popInt64 pops the value as a number, known is false if the number is
+// unknown at analysis
+func (a *analyzer) popInt64(s *absState, pc uint32) (n int64, known bool, ok bool) {
+	v, ok := a.pop(s, pc)
+	if !ok || !v.known {
+		return 0, false, ok
+	}
+	n, err := AsInt64(v.data)
+	return n, err == nil, true
+}
+
+// unknownStack gives up tracking the stack of state after an instruction
+// whose stack effect is unknown, the guard values on stack may be any of
+// the untracked values
+func unknownStack(s *absState, push bool) {
+	for _, v := range s.stack {
+		s.hiddenGuard = s.hiddenGuard || v.guard
+	}
+	s.stack, s.open, s.imprecise = nil, true, true
+	if push {
+		s.stack = append(s.stack, absValue{guard: s.hiddenGuard})
+	}
+}
+
+// checkMultiSig applies CHECKMULTISIG
+//
+//	[... SIG SIG SIG PREDICATEHASH PUB PUB PUB M N] -> [... RESULT]
+func (a *analyzer) checkMultiSig(s *absState, pc uint32) bool {
+	npubkeys, npubkeysKnown, ok := a.popInt64(s, pc)
+	if !ok {
+		return false
+	}
+	nsigs, nsigsKnown, ok := a.popInt64(s, pc)
+	if !ok {
+		return false
+	}
+	if !npubkeysKnown || !nsigsKnown || npubkeys < 0 || nsigs < 0 || npubkeys > int64(len(a.program)) || nsigs > npubkeys {
+		unknownStack(s, false)
+		s.stack = append(s.stack, absValue{guard: true})
+		return true
+	}
+
+	s.gas = a.addGas(s.gas, 1024*npubkeys)
+	n := int(npubkeys + 1 + nsigs)
+	if !a.need(s, pc, n) {
+		return false
+	}
+	s.stack = append(s.stack[:len(s.stack)-n], absValue{guard: true})
+	return true
+}
+
+// checkPredicate applies CHECKPREDICATE, the predicate is analyzed with
+// the n values moved from stack, or all the values if n is negative
+//
+//	[... ARGS N PREDICATE LIMIT] -> [... RESULT]
+func (a *analyzer) checkPredicate(s *absState, pc uint32) bool {
+	limit, limitKnown, ok := a.popInt64(s, pc)
+	if !ok {
+		return false
+	}
+	predicate, ok := a.pop(s, pc)
+	if !ok {
+		return false
+	}
+	nValue, ok := a.pop(s, pc)
+	if !ok {
+		return false
+	}
+
+	n, err := AsInt64(nValue.data)
+	all := nValue.known && err == nil && n < 0
+	if nValue.isDepth && nValue.depthOf == len(s.stack) && nValue.below == s.below {
+		all = true
+	}
+
+	init := &absState{visited: make(map[uint32]*absState)}
+	switch {
+	case all:
+		init.stack, init.open, init.below, init.imprecise, init.hiddenGuard = s.stack, s.open, s.below, s.imprecise, s.hiddenGuard
+		s.stack, s.open = nil, false
+
+	case nValue.known && err == nil:
+		if !a.need(s, pc, int(n)) {
+			return false
+		}
+		init.stack = append([]absValue{}, s.stack[len(s.stack)-int(n):]...)
+		s.stack = s.stack[:len(s.stack)-int(n)]
+
+	default:
+		unknownStack(s, false)
+		init.open, init.imprecise, init.hiddenGuard = true, true, s.hiddenGuard
+	}
+
+	child, ok := a.analyzePredicate(pc, predicate, init)
+	if !ok {
+		// the predicate can't be analyzed, it is taken as guarded
+		s.stack = append(s.stack, absValue{guard: true})
+		switch {
+		case !limitKnown || limit == 0:
+			a.unbounded = true
+		case limit > 0:
+			s.gas = a.addGas(s.gas, limit)
+		}
+		return true
+	}
+
+	gas := child.gas
+	if limitKnown && limit > 0 && limit < gas {
+		gas = limit
+	}
+	s.gas = a.addGas(s.gas, gas)
+	a.unbounded = a.unbounded || child.unbounded
+
+	if all {
+		// the witness arguments under stack are consumed by the predicate
+		s.below = child.arguments
+		s.imprecise = s.imprecise || child.imprecise
+	}
+
+	result := absValue{guard: child.succeeds && len(child.unguarded) == 0}
+	if !child.succeeds {
+		result = absValue{known: true, data: []byte{}}
+	}
+	s.stack = append(s.stack, result)
+	return true
+}
+
+// analyzePredicate runs the predicate from the state by a child analyzer,
+// ok is false if the predicate can't be analyzed
+func (a *analyzer) analyzePredicate(pc uint32, predicate absValue, init *absState) (*analyzer, bool) {
+	if !predicate.known || a.depth >= maxAnalyzeDepth {
+		return nil, false
+	}
+	insts, err := ParseProgram(predicate.data)
+	if err != nil {
+		return nil, false
+	}
+
+	child := &analyzer{
+		program: predicate.data,
+		depth:   a.depth + 1,
+		steps:   a.steps,
+		reached: make(map[uint32]bool),
+		report: func(offset uint32, kind, message string) {
+			a.report(pc, kind, fmt.Sprintf("%s, at offset %d of predicate", message, offset))
+		},
+	}
+	if !child.run(insts, init) {
+		return nil, false
+	}
+	return child, true
+}
+
+func (a *analyzer) addGas(gas, cost int64) int64 {
+	sum, ok := checked.AddInt64(gas, cost)
+	if !ok {
+		a.unbounded = true
+		return gas
+	}
+	return sum
+}
+
+// staticCost returns the cost of instruction which doesn't depend on the
+// runtime values, the memory cost of pushdata is added when it is pushed
+func staticCost(inst Instruction) int64 {
+	if isExpansion[inst.Op] || inst.IsPushdata() {
+		return 1
+	}
+
+	switch inst.Op {
+	case OP_CHECKPREDICATE:
+		return 256
+	case OP_CHECKMULTISIG:
+		// 1024 for each public key is added by checkMultiSig
+		return 0
+	}
+
+	if effect, ok := stackEffects[inst.Op]; ok {
+		return effect.cost
+	}
+	if perm, ok := permutations[inst.Op]; ok {
+		return perm.cost
+	}
+	return 1
+}
+
+type stackEffect struct {
+	pops, pushes int
+	cost         int64
+}
+
+// stackEffects is the stack effects of the instructions popping the inputs
+// and pushing the outputs of unknown values
+var stackEffects = map[Op]stackEffect{
+	OP_NOP: {0, 0, 1},
+
+	OP_CAT:         {2, 1, 4},
+	OP_SUBSTR:      {3, 1, 4},
+	OP_LEFT:        {2, 1, 4},
+	OP_RIGHT:       {2, 1, 4},
+	OP_CATPUSHDATA: {2, 1, 4},
+
+	OP_INVERT:      {1, 1, 1},
+	OP_AND:         {2, 1, 1},
+	OP_OR:          {2, 1, 1},
+	OP_XOR:         {2, 1, 1},
+	OP_EQUAL:       {2, 1, 1},
+	OP_EQUALVERIFY: {2, 0, 1},
+
+	OP_1ADD:               {1, 1, 2},
+	OP_1SUB:               {1, 1, 2},
+	OP_2MUL:               {1, 1, 2},
+	OP_2DIV:               {1, 1, 2},
+	OP_NEGATE:             {1, 1, 2},
+	OP_ABS:                {1, 1, 2},
+	OP_NOT:                {1, 1, 2},
+	OP_0NOTEQUAL:          {1, 1, 2},
+	OP_ADD:                {2, 1, 2},
+	OP_SUB:                {2, 1, 2},
+	OP_MUL:                {2, 1, 8},
+	OP_DIV:                {2, 1, 8},
+	OP_MOD:                {2, 1, 8},
+	OP_LSHIFT:             {2, 1, 8},
+	OP_RSHIFT:             {2, 1, 8},
+	OP_BOOLAND:            {2, 1, 2},
+	OP_BOOLOR:             {2, 1, 2},
+	OP_NUMEQUAL:           {2, 1, 2},
+	OP_NUMEQUALVERIFY:     {2, 0, 2},
+	OP_NUMNOTEQUAL:        {2, 1, 2},
+	OP_LESSTHAN:           {2, 1, 2},
+	OP_GREATERTHAN:        {2, 1, 2},
+	OP_LESSTHANOREQUAL:    {2, 1, 2},
+	OP_GREATERTHANOREQUAL: {2, 1, 2},
+	OP_MIN:                {2, 1, 2},
+	OP_MAX:                {2, 1, 2},
+	OP_WITHIN:             {3, 1, 4},
+
+	OP_SHA256:    {1, 1, 64},
+	OP_SHA3:      {1, 1, 64},
+	OP_HASH160:   {1, 1, 64},
+	OP_CHECKSIG:  {3, 1, 1024},
+	OP_TXSIGHASH: {0, 1, 256},
+
+	OP_CHECKOUTPUT: {5, 1, 16},
+	OP_ASSET:       {0, 1, 1},
+	OP_AMOUNT:      {0, 1, 1},
+	OP_PROGRAM:     {0, 1, 1},
+	OP_INDEX:       {0, 1, 1},
+	OP_ENTRYID:     {0, 1, 1},
+	OP_OUTPUTID:    {0, 1, 1},
+	OP_BLOCKHEIGHT: {0, 1, 1},
+
+	OP_JUMP:         {0, 0, 1},
+	OP_JUMPIF:       {1, 0, 1},
+	OP_VERIFY:       {1, 0, 1},
+	OP_FAIL:         {0, 0, 1},
+	OP_TOALTSTACK:   {1, 0, 2},
+	OP_FROMALTSTACK: {0, 1, 2},
+	OP_IFDUP:        {1, 2, 1},
+	OP_DEPTH:        {0, 1, 1},
+	OP_SIZE:         {1, 2, 1},
+	OP_PICK:         {1, 1, 2},
+	OP_ROLL:         {1, 1, 2},
+}
+
+// permutation moves the top n values of stack, out is the indexes of the
+// top values pushed in order
+type permutation struct {
+	n    int
+	out  []int
+	cost int64
+}
+
+var permutations = map[Op]permutation{
+	OP_2DROP: {2, []int{}, 2},
+	OP_2DUP:  {2, []int{0, 1, 0, 1}, 2},
+	OP_3DUP:  {3, []int{0, 1, 2, 0, 1, 2}, 3},
+	OP_2OVER: {4, []int{0, 1, 2, 3, 0, 1}, 2},
+	OP_2ROT:  {6, []int{2, 3, 4, 5, 0, 1}, 2},
+	OP_2SWAP: {4, []int{2, 3, 0, 1}, 2},
+	OP_DROP:  {1, []int{}, 1},
+	OP_DUP:   {1, []int{0, 0}, 1},
+	OP_NIP:   {2, []int{1}, 1},
+	OP_OVER:  {2, []int{0, 1, 0}, 1},
+	OP_ROT:   {3, []int{1, 2, 0}, 2},
+	OP_SWAP:  {2, []int{1, 0}, 1},
+	OP_TUCK:  {2, []int{1, 0, 1}, 1},
+}
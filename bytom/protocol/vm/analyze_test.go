@@ -0,0 +1,179 @@
+package vm
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeProgram(t *testing.T) {
+	var (
+		pubkey = "0x" + strings.Repeat("01", 32)
+		sig    = "0x" + strings.Repeat("02", 64)
+		check  = "TXSIGHASH " + pubkey + " " + sig + " CHECKSIG"
+	)
+
+	cases := []struct {
+		desc      string
+		src       string
+		nargs     int
+		gasLimit  int64
+		want      []string
+		unbounded bool
+	}{
+		{
+			desc: "checked signature",
+			src:  check,
+		},
+		{
+			desc: "verified signature",
+			src:  check + " VERIFY TRUE",
+		},
+		{
+			desc: "jumped on signature",
+			src:  check + " JUMPIF:$ok FAIL $ok TRUE",
+		},
+		{
+			desc: "conjunction with signature",
+			src:  check + " TRUE BOOLAND",
+		},
+		{
+			desc: "dropped signature",
+			src:  check + " DROP TRUE",
+			want: []string{WarnNoSignature},
+		},
+		{
+			desc: "disjunction with signature",
+			src:  check + " TRUE BOOLOR",
+			want: []string{WarnNoSignature},
+		},
+		{
+			desc: "dropped multisig",
+			src:  "TXSIGHASH " + pubkey + " " + sig + " 1 1 CHECKMULTISIG DROP TRUE",
+			want: []string{WarnNoSignature},
+		},
+		{
+			desc: "no signature",
+			src:  "TRUE",
+			want: []string{WarnNoSignature},
+		},
+		{
+			desc: "fail",
+			src:  "FAIL",
+			want: []string{WarnNeverSucceeds},
+		},
+		{
+			desc:      "jump to itself",
+			src:       "JUMP:0",
+			want:      []string{WarnGas, WarnNeverSucceeds},
+			unbounded: true,
+		},
+		{
+			desc:      "loop without exit",
+			src:       "TRUE $loop JUMP:$loop",
+			want:      []string{WarnGas, WarnNeverSucceeds},
+			unbounded: true,
+		},
+		{
+			desc:      "loop pushing values",
+			src:       "$loop TRUE JUMP:$loop",
+			want:      []string{WarnGas, WarnNeverSucceeds},
+			unbounded: true,
+		},
+		{
+			desc:      "loop with unknown exit",
+			src:       "$loop JUMPIF:$loop " + check,
+			nargs:     -1,
+			want:      []string{WarnGas},
+			unbounded: true,
+		},
+		{
+			desc:      "countdown loop",
+			src:       "$loop DROP DEPTH JUMPIF:$loop " + check,
+			nargs:     3,
+			want:      []string{WarnGas},
+			unbounded: true,
+		},
+		{
+			desc:      "loop dropping signature",
+			src:       check + " $loop DROP DEPTH JUMPIF:$loop TRUE",
+			nargs:     2,
+			want:      []string{WarnGas, WarnNoSignature},
+			unbounded: true,
+		},
+		{
+			desc:  "stack underflow",
+			src:   "ADD",
+			nargs: 1,
+			want:  []string{WarnNeverSucceeds, WarnStackUnderflow},
+		},
+		{
+			desc: "jump beyond program",
+			src:  "JUMP:100",
+			want: []string{WarnBadJump, WarnNeverSucceeds},
+		},
+		{
+			desc: "jump into instruction",
+			src:  "JUMP:6 0x0102 " + check,
+			want: []string{WarnBadJump, WarnUnreachable},
+		},
+		{
+			desc: "unreachable code",
+			src:  "JUMP:$end " + check + " $end " + check,
+			want: []string{WarnUnreachable},
+		},
+		{
+			desc: "segwit program",
+			src:  "0 0x" + strings.Repeat("03", 20),
+			want: []string{WarnSegwit},
+		},
+		{
+			desc:     "gas limit",
+			src:      check,
+			gasLimit: 100,
+			want:     []string{WarnGas},
+		},
+		{
+			desc:      "unbounded gas limit",
+			src:       "$loop JUMPIF:$loop " + check,
+			nargs:     -1,
+			gasLimit:  100000,
+			want:      []string{WarnGas, WarnGas},
+			unbounded: true,
+		},
+	}
+
+	for _, c := range cases {
+		program, err := Assemble(c.src)
+		if err != nil {
+			t.Fatalf("%s: %v", c.desc, err)
+		}
+		analysis, err := AnalyzeProgram(program, c.nargs, c.gasLimit)
+		if err != nil {
+			t.Fatalf("%s: %v", c.desc, err)
+		}
+
+		var got []string
+		for _, w := range analysis.Warnings {
+			got = append(got, w.Kind)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got warnings %v, want %v", c.desc, got, c.want)
+		}
+		if analysis.GasUnbounded != c.unbounded {
+			t.Errorf("%s: got gas unbounded %v, want %v", c.desc, analysis.GasUnbounded, c.unbounded)
+		}
+	}
+}
+
+func TestAnalyzeExpansion(t *testing.T) {
+	analysis, err := AnalyzeProgram([]byte{0x50, byte(OP_TRUE)}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(analysis.Warnings) != 2 || analysis.Warnings[0].Kind != WarnExpansion || analysis.Warnings[0].Offset != 0 {
+		t.Errorf("got warnings %v, want expansion at 0 and no signature", analysis.Warnings)
+	}
+}
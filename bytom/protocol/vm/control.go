@@ -69,6 +69,7 @@ func opCheckPredicate(vm *virtualMachine) error {
 		runLimit:  limit,
 		depth:     vm.depth + 1,
 		dataStack: append([][]byte{}, vm.dataStack[l-n:]...),
+		tracer:    vm.tracer,
 	}
 	vm.dataStack = vm.dataStack[:l-n]
 
@@ -0,0 +1,158 @@
+package vm
+
+import (
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
+// TraceStep is the state of VM after an op is executed.
+type TraceStep struct {
+	// Depth is the depth of the VM, the child VM spawned by CHECKPREDICATE
+	// has depth+1.
+	Depth int
+	PC    uint32
+	Op    Op
+	Data  []byte
+
+	DataStack    [][]byte
+	AltStack     [][]byte
+	RunLimit     int64
+	DeferredCost int64
+
+	// Err is the error of executing the op.
+	Err error
+}
+
+func (vm *virtualMachine) traceStep(pc uint32, inst Instruction, err error) *TraceStep {
+	return &TraceStep{
+		Depth:        vm.depth,
+		PC:           pc,
+		Op:           inst.Op,
+		Data:         inst.Data,
+		DataStack:    append([][]byte{}, vm.dataStack...),
+		AltStack:     append([][]byte{}, vm.altStack...),
+		RunLimit:     vm.runLimit,
+		DeferredCost: vm.deferredCost,
+		Err:          err,
+	}
+}
+
+// Debugger runs the program step by step like Verify, the trace is recorded
+// for each debugger instead of the global TraceOut.
+type Debugger struct {
+	vm          *virtualMachine
+	args        [][]byte
+	breakpoints map[uint32]bool
+	trace       []*TraceStep
+
+	done bool
+	err  error
+}
+
+// NewDebugger create the debugger of the program in context, the arguments of
+// context are pushed.
+func NewDebugger(context *Context, gasLimit int64) (*Debugger, error) {
+	if context.VMVersion != 1 {
+		return nil, ErrUnsupportedVM
+	}
+
+	d := &Debugger{
+		args:        context.Arguments,
+		breakpoints: make(map[uint32]bool),
+	}
+	d.vm = &virtualMachine{
+		expansionReserved: context.TxVersion != nil && *context.TxVersion == 1,
+		program:           context.Code,
+		runLimit:          gasLimit,
+		context:           context,
+		tracer:            func(step *TraceStep) { d.trace = append(d.trace, step) },
+	}
+
+	for i, arg := range context.Arguments {
+		if err := d.vm.push(arg, false); err != nil {
+			return nil, errors.Wrapf(err, "pushing initial argument %d", i)
+		}
+	}
+
+	if len(d.vm.program) == 0 {
+		d.finish(nil)
+	}
+	return d, nil
+}
+
+// SetBreakpoint stop Continue before the op at pc of the program is executed.
+func (d *Debugger) SetBreakpoint(pc uint32) {
+	d.breakpoints[pc] = true
+}
+
+// ClearBreakpoint remove the breakpoint at pc.
+func (d *Debugger) ClearBreakpoint(pc uint32) {
+	delete(d.breakpoints, pc)
+}
+
+// PC return the location of the next op to execute.
+func (d *Debugger) PC() uint32 {
+	return d.vm.pc
+}
+
+// Done return whether the program is finished.
+func (d *Debugger) Done() bool {
+	return d.done
+}
+
+// Trace return the states after each executed op, including the ops of the
+// child VM.
+func (d *Debugger) Trace() []*TraceStep {
+	return d.trace
+}
+
+// Result return the gas left and the error of program like Verify, it should
+// be called after the program is done.
+func (d *Debugger) Result() (gasLeft int64, err error) {
+	return d.vm.runLimit, d.err
+}
+
+// Step execute the next op of program, the CHECKPREDICATE op runs its child
+// VM to the end in one step.
+func (d *Debugger) Step() (err error) {
+	if d.done {
+		return d.err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if rErr, ok := r.(error); ok {
+				err = errors.Sub(ErrUnexpected, rErr)
+			} else {
+				err = errors.Wrap(ErrUnexpected, r)
+			}
+		}
+		if err != nil || d.vm.pc >= uint32(len(d.vm.program)) {
+			err = d.finish(err)
+		}
+	}()
+
+	return d.vm.step()
+}
+
+// Continue execute the ops until the program is done or a breakpoint is met,
+// at least one op is executed.
+func (d *Debugger) Continue() error {
+	for {
+		if err := d.Step(); err != nil || d.done {
+			return err
+		}
+		if d.breakpoints[d.vm.pc] {
+			return nil
+		}
+	}
+}
+
+func (d *Debugger) finish(err error) error {
+	if err == nil && d.vm.falseResult() {
+		err = ErrFalseVMResult
+	}
+
+	d.done = true
+	d.err = wrapErr(err, d.vm, d.args)
+	return d.err
+}
@@ -29,6 +29,10 @@ type virtualMachine struct {
 	// In each of these stacks, stack[len(stack)-1] is the top element.
 	dataStack [][]byte
 	altStack  [][]byte
+
+	// tracer - if non-nil - receives the state after each step, it is
+	// inherited by the child vm
+	tracer func(*TraceStep)
 }
 
 // TraceOut - if non-nil - will receive trace output during
@@ -90,13 +94,17 @@ func (vm *virtualMachine) run() error {
 	return nil
 }
 
-func (vm *virtualMachine) step() error {
+func (vm *virtualMachine) step() (err error) {
 	inst, err := ParseOp(vm.program, vm.pc)
 	if err != nil {
 		return err
 	}
 
 	vm.nextPC = vm.pc + inst.Len
+	if vm.tracer != nil {
+		pc := vm.pc
+		defer func() { vm.tracer(vm.traceStep(pc, inst, err)) }()
+	}
 
 	if TraceOut != nil {
 		opname := inst.Op.String()
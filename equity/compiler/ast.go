@@ -0,0 +1,318 @@
+package compiler
+
+import (
+	"encoding/hex"
+	"strconv"
+
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+)
+
+// Contract is a compiled Equity contract.
+type Contract struct {
+	// Name is the contract name.
+	Name string `json:"name"`
+
+	// Params is the list of contract parameters.
+	Params []*Param `json:"params"`
+
+	// Clauses is the list of contract clauses.
+	Clauses []*Clause `json:"clauses"`
+
+	// Value is the names of the amount and asset of the value locked by
+	// the contract.
+	Value ValueInfo `json:"value"`
+
+	// Body is the bytecode of the contract body. This is not a complete
+	// program, the instantiated program pushes the contract arguments in
+	// reverse order and runs the body by CHECKPREDICATE.
+	Body chainjson.HexBytes `json:"body_bytecode"`
+
+	// Opcodes is the human-readable string of opcodes corresponding to
+	// Body.
+	Opcodes string `json:"body_opcodes"`
+
+	params []*binding
+}
+
+// Param is a contract or clause parameter.
+type Param struct {
+	// Name is the parameter name.
+	Name string `json:"name"`
+
+	// Type is the declared parameter type.
+	Type typeDesc `json:"type"`
+
+	// ArgType is the argument type accepted by ConvertContractArg for
+	// the parameter.
+	ArgType string `json:"arg_type"`
+}
+
+// Clause is a compiled contract clause.
+type Clause struct {
+	// Name is the clause name.
+	Name string `json:"name"`
+
+	// Selector is the value of the clause selector, which must be
+	// pushed after the clause arguments when the contract has more
+	// than one clause.
+	Selector int64 `json:"selector"`
+
+	// Params is the list of clause parameters.
+	Params []*Param `json:"params"`
+
+	// BlockHeight is the list of expressions passed to above()/below()
+	// in this clause.
+	BlockHeight []string `json:"blockheight,omitempty"`
+
+	// HashCalls is the list of hash functions and their arguments used
+	// in this clause.
+	HashCalls []HashCall `json:"hash_calls,omitempty"`
+
+	// Values is the list of values locked or unlocked by this clause.
+	Values []ValueInfo `json:"values"`
+
+	params     []*binding
+	statements []statement
+}
+
+// HashCall describes a call to a hash function.
+type HashCall struct {
+	// HashType is "sha3" or "sha256".
+	HashType string `json:"hash_type"`
+
+	// Arg is the expression passed to the hash function.
+	Arg string `json:"arg"`
+
+	// ArgType is the type of Arg.
+	ArgType string `json:"arg_type"`
+}
+
+// ValueInfo describes how a blockchain value is used in a contract
+// clause.
+type ValueInfo struct {
+	// Program is the program expression used to the lock the value, if
+	// the value is locked with "lock." If it's unlocked with "unlock"
+	// instead, this is empty.
+	Program string `json:"program,omitempty"`
+
+	// Asset is the expression describing the asset type the value must
+	// have, as it appears in a clause's "lock" or "unlock" statement.
+	Asset string `json:"asset,omitempty"`
+
+	// Amount is the expression describing the amount the value must
+	// have, as it appears in a clause's "lock" or "unlock" statement.
+	Amount string `json:"amount,omitempty"`
+}
+
+type statement interface {
+	countVarRefs(map[*binding]int)
+}
+
+type verifyStatement struct {
+	pos  position
+	expr expression
+}
+
+func (s *verifyStatement) countVarRefs(counts map[*binding]int) {
+	s.expr.countVarRefs(counts)
+}
+
+type lockStatement struct {
+	pos     position
+	amount  expression
+	asset   expression
+	program expression
+
+	// index is the output index of the lock, starting from 0 in each
+	// clause
+	index int64
+}
+
+func (s *lockStatement) countVarRefs(counts map[*binding]int) {
+	s.amount.countVarRefs(counts)
+	s.asset.countVarRefs(counts)
+	s.program.countVarRefs(counts)
+}
+
+type unlockStatement struct {
+	pos    position
+	amount expression
+	asset  expression
+}
+
+// countVarRefs counts nothing, since unlock statement emits no code
+func (s *unlockStatement) countVarRefs(counts map[*binding]int) {}
+
+type defineStatement struct {
+	pos      position
+	variable *binding
+	expr     expression
+}
+
+func (s *defineStatement) countVarRefs(counts map[*binding]int) {
+	s.expr.countVarRefs(counts)
+}
+
+type expression interface {
+	String() string
+	typ() typeDesc
+	countVarRefs(map[*binding]int)
+}
+
+type binaryExpr struct {
+	pos         position
+	left, right expression
+	op          *binaryOp
+	t           typeDesc
+}
+
+func (e *binaryExpr) String() string {
+	return "(" + e.left.String() + " " + e.op.op + " " + e.right.String() + ")"
+}
+
+func (e *binaryExpr) typ() typeDesc { return e.t }
+
+func (e *binaryExpr) countVarRefs(counts map[*binding]int) {
+	e.left.countVarRefs(counts)
+	e.right.countVarRefs(counts)
+}
+
+type unaryExpr struct {
+	pos  position
+	op   *unaryOp
+	expr expression
+}
+
+func (e *unaryExpr) String() string { return e.op.op + e.expr.String() }
+
+func (e *unaryExpr) typ() typeDesc { return e.op.result }
+
+func (e *unaryExpr) countVarRefs(counts map[*binding]int) {
+	e.expr.countVarRefs(counts)
+}
+
+type callExpr struct {
+	pos     position
+	builtin *builtin
+	args    []expression
+}
+
+func (e *callExpr) String() string {
+	s := e.builtin.name + "("
+	for i, arg := range e.args {
+		if i > 0 {
+			s += ", "
+		}
+		s += arg.String()
+	}
+	return s + ")"
+}
+
+func (e *callExpr) typ() typeDesc {
+	if e.builtin.name == "sha3" || e.builtin.name == "sha256" {
+		return hashOf(e.builtin.name, e.args[0].typ())
+	}
+	return e.builtin.result
+}
+
+func (e *callExpr) countVarRefs(counts map[*binding]int) {
+	for _, arg := range e.args {
+		arg.countVarRefs(counts)
+	}
+}
+
+type listExpr struct {
+	pos   position
+	items []expression
+}
+
+func (e *listExpr) String() string {
+	s := "["
+	for i, item := range e.items {
+		if i > 0 {
+			s += ", "
+		}
+		s += item.String()
+	}
+	return s + "]"
+}
+
+func (e *listExpr) typ() typeDesc { return listType }
+
+func (e *listExpr) countVarRefs(counts map[*binding]int) {
+	for _, item := range e.items {
+		item.countVarRefs(counts)
+	}
+}
+
+// binding is a name bound to a value on the stack, which is a contract
+// parameter, a clause parameter or a defined variable.
+type binding struct {
+	name string
+	t    typeDesc
+}
+
+type varRef struct {
+	pos      position
+	name     string
+	variable *binding
+}
+
+func (e *varRef) String() string { return e.name }
+
+func (e *varRef) typ() typeDesc { return e.variable.t }
+
+func (e *varRef) countVarRefs(counts map[*binding]int) {
+	counts[e.variable]++
+}
+
+// valueRef refers to the amount or asset of the value locked by the
+// contract, which are introspected by AMOUNT and ASSET
+type valueRef struct {
+	pos  position
+	name string
+	t    typeDesc
+}
+
+func (e *valueRef) String() string { return e.name }
+
+func (e *valueRef) typ() typeDesc { return e.t }
+
+func (e *valueRef) countVarRefs(counts map[*binding]int) {}
+
+type integerLiteral int64
+
+func (e integerLiteral) String() string { return strconv.FormatInt(int64(e), 10) }
+
+func (e integerLiteral) typ() typeDesc { return integerType }
+
+func (e integerLiteral) countVarRefs(counts map[*binding]int) {}
+
+type bytesLiteral []byte
+
+func (e bytesLiteral) String() string { return "0x" + hex.EncodeToString(e) }
+
+func (e bytesLiteral) typ() typeDesc { return bytesType }
+
+func (e bytesLiteral) countVarRefs(counts map[*binding]int) {}
+
+type stringLiteral string
+
+func (e stringLiteral) String() string { return strconv.Quote(string(e)) }
+
+func (e stringLiteral) typ() typeDesc { return stringType }
+
+func (e stringLiteral) countVarRefs(counts map[*binding]int) {}
+
+type booleanLiteral bool
+
+func (e booleanLiteral) String() string {
+	if e {
+		return "true"
+	}
+	return "false"
+}
+
+func (e booleanLiteral) typ() typeDesc { return booleanType }
+
+func (e booleanLiteral) countVarRefs(counts map[*binding]int) {}
@@ -0,0 +1,90 @@
+package compiler
+
+import "github.com/bytom-community/wasm/bytom/protocol/vm"
+
+type builtin struct {
+	name   string
+	ops    []vm.Op
+	args   []typeDesc
+	result typeDesc
+
+	// reverseArgs tells the arguments are pushed in reverse order
+	reverseArgs bool
+}
+
+// anyType accepts an argument of any type represented by bytes
+const anyType typeDesc = ""
+
+var builtins = []*builtin{
+	{"sha3", []vm.Op{vm.OP_SHA3}, []typeDesc{anyType}, "", false},
+	{"sha256", []vm.Op{vm.OP_SHA256}, []typeDesc{anyType}, "", false},
+	{"size", []vm.Op{vm.OP_SIZE, vm.OP_NIP}, []typeDesc{anyType}, integerType, false},
+	{"abs", []vm.Op{vm.OP_ABS}, []typeDesc{integerType}, integerType, false},
+	{"min", []vm.Op{vm.OP_MIN}, []typeDesc{integerType, integerType}, integerType, false},
+	{"max", []vm.Op{vm.OP_MAX}, []typeDesc{integerType, integerType}, integerType, false},
+	{"checkTxSig", []vm.Op{vm.OP_TXSIGHASH, vm.OP_SWAP, vm.OP_CHECKSIG}, []typeDesc{publicKeyType, signatureType}, booleanType, true},
+	{"concat", []vm.Op{vm.OP_CAT}, []typeDesc{anyType, anyType}, stringType, false},
+	{"concatpush", []vm.Op{vm.OP_CATPUSHDATA}, []typeDesc{anyType, anyType}, stringType, false},
+	{"below", []vm.Op{vm.OP_BLOCKHEIGHT, vm.OP_GREATERTHAN}, []typeDesc{integerType}, booleanType, false},
+	{"above", []vm.Op{vm.OP_BLOCKHEIGHT, vm.OP_LESSTHAN}, []typeDesc{integerType}, booleanType, false},
+	{"checkTxMultiSig", []vm.Op{vm.OP_CHECKMULTISIG}, []typeDesc{listType, listType}, booleanType, true},
+}
+
+func lookupBuiltin(name string) *builtin {
+	for _, b := range builtins {
+		if b.name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+type binaryOp struct {
+	op         string
+	precedence int
+	ops        []vm.Op
+
+	// numOps is used instead of ops when the operands are numeric, it
+	// is empty if the operator only applies to one kind of operands
+	numOps []vm.Op
+
+	left, right, result typeDesc
+}
+
+var binaryOps = []*binaryOp{
+	{"||", 1, []vm.Op{vm.OP_BOOLOR}, nil, booleanType, booleanType, booleanType},
+	{"&&", 2, []vm.Op{vm.OP_BOOLAND}, nil, booleanType, booleanType, booleanType},
+
+	{">", 3, []vm.Op{vm.OP_GREATERTHAN}, nil, integerType, integerType, booleanType},
+	{"<", 3, []vm.Op{vm.OP_LESSTHAN}, nil, integerType, integerType, booleanType},
+	{">=", 3, []vm.Op{vm.OP_GREATERTHANOREQUAL}, nil, integerType, integerType, booleanType},
+	{"<=", 3, []vm.Op{vm.OP_LESSTHANOREQUAL}, nil, integerType, integerType, booleanType},
+
+	{"==", 3, []vm.Op{vm.OP_EQUAL}, []vm.Op{vm.OP_NUMEQUAL}, anyType, anyType, booleanType},
+	{"!=", 3, []vm.Op{vm.OP_EQUAL, vm.OP_NOT}, []vm.Op{vm.OP_NUMNOTEQUAL}, anyType, anyType, booleanType},
+
+	{"^", 4, []vm.Op{vm.OP_XOR}, nil, integerType, integerType, integerType},
+	{"|", 4, []vm.Op{vm.OP_OR}, nil, integerType, integerType, integerType},
+	{"+", 4, []vm.Op{vm.OP_ADD}, nil, integerType, integerType, integerType},
+	{"-", 4, []vm.Op{vm.OP_SUB}, nil, integerType, integerType, integerType},
+
+	{"&", 5, []vm.Op{vm.OP_AND}, nil, integerType, integerType, integerType},
+	{"<<", 5, []vm.Op{vm.OP_LSHIFT}, nil, integerType, integerType, integerType},
+	{">>", 5, []vm.Op{vm.OP_RSHIFT}, nil, integerType, integerType, integerType},
+	{"%", 5, []vm.Op{vm.OP_MOD}, nil, integerType, integerType, integerType},
+	{"*", 5, []vm.Op{vm.OP_MUL}, nil, integerType, integerType, integerType},
+	{"/", 5, []vm.Op{vm.OP_DIV}, nil, integerType, integerType, integerType},
+}
+
+type unaryOp struct {
+	op      string
+	ops     []vm.Op
+	operand typeDesc
+	result  typeDesc
+}
+
+var unaryOps = []*unaryOp{
+	{"-", []vm.Op{vm.OP_NEGATE}, integerType, integerType},
+	{"~", []vm.Op{vm.OP_INVERT}, integerType, integerType},
+	{"!", []vm.Op{vm.OP_NOT}, booleanType, booleanType},
+}
@@ -0,0 +1,358 @@
+package compiler
+
+import (
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+)
+
+// Compile parses the Equity source and compiles the contracts in it.
+//
+// The contract arguments are pushed in reverse order before the
+// contract body, so the first parameter is on the top of stack. The
+// clause arguments are pushed in reverse order too, followed by the
+// clause selector when there is more than one clause:
+//
+//	[... clauseArgN ... clauseArg1 <clause selector> paramN ... param1]
+func Compile(src string) ([]*Contract, error) {
+	contracts, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, contract := range contracts {
+		if err := compileContract(contract); err != nil {
+			return nil, errors.WithDetailf(err, "compiling contract %s", contract.Name)
+		}
+	}
+	return contracts, nil
+}
+
+func compileContract(contract *Contract) error {
+	b := vmutil.NewBuilder()
+	g := &generator{last: -1}
+
+	targets := make([]int, len(contract.Clauses))
+	if len(contract.Clauses) > 1 {
+		// move the clause selector to the top of stack, then jump to
+		// the selected clause, clause 0 and 1 are selected by the last
+		// JUMPIF which consumes the selector
+		g.roll(len(contract.params))
+		b.AddRawBytes(g.flush())
+		for i := len(contract.Clauses) - 1; i >= 1; i-- {
+			targets[i] = b.NewJumpTarget()
+			if i >= 2 {
+				b.AddOp(vm.OP_DUP)
+				b.AddInt64(int64(i))
+				b.AddOp(vm.OP_NUMEQUAL)
+			}
+			b.AddJumpIf(targets[i])
+		}
+	}
+
+	end := b.NewJumpTarget()
+	for i, clause := range contract.Clauses {
+		if i > 0 {
+			b.SetJumpTarget(targets[i])
+			if i >= 2 {
+				b.AddOp(vm.OP_DROP)
+			}
+		}
+
+		if err := g.compileClause(contract, clause); err != nil {
+			return errors.WithDetailf(err, "compiling clause %s", clause.Name)
+		}
+		b.AddRawBytes(g.flush())
+		if i < len(contract.Clauses)-1 {
+			b.AddJump(end)
+		}
+	}
+	b.SetJumpTarget(end)
+
+	body, err := b.Build()
+	if err != nil {
+		return err
+	}
+
+	opcodes, err := vm.Disassemble(body)
+	if err != nil {
+		return err
+	}
+
+	contract.Body, contract.Opcodes = body, opcodes
+	return nil
+}
+
+// generator emits the code of clauses, it keeps track of the stack so
+// that the variables can be found by depth
+type generator struct {
+	// code is the straight-line code not flushed yet, last is the
+	// offset of its last instruction
+	code []byte
+	last int
+
+	// stack is the model of the data stack from bottom to top, nil is
+	// an anonymous value
+	stack []*binding
+
+	// counts is the number of remaining references of the variables,
+	// the variable is rolled instead of picked at its last reference
+	counts map[*binding]int
+}
+
+func (g *generator) compileClause(contract *Contract, clause *Clause) error {
+	g.stack = nil
+	for i := len(clause.params) - 1; i >= 0; i-- {
+		g.stack = append(g.stack, clause.params[i])
+	}
+	for i := len(contract.params) - 1; i >= 0; i-- {
+		g.stack = append(g.stack, contract.params[i])
+	}
+
+	g.counts = make(map[*binding]int)
+	for _, stmt := range clause.statements {
+		stmt.countVarRefs(g.counts)
+	}
+
+	// the last verify or lock statement leaves its result as the result
+	// of program, unless a define statement follows it
+	result := -1
+	for i, stmt := range clause.statements {
+		switch stmt.(type) {
+		case *verifyStatement, *lockStatement:
+			result = i
+		case *defineStatement:
+			result = -1
+		}
+	}
+
+	for i, stmt := range clause.statements {
+		switch stmt := stmt.(type) {
+		case *verifyStatement:
+			if err := g.expr(stmt.expr); err != nil {
+				return err
+			}
+
+		case *lockStatement:
+			g.int64(stmt.index)
+			g.push(nil)
+			if err := g.expr(stmt.amount); err != nil {
+				return err
+			}
+			if err := g.expr(stmt.asset); err != nil {
+				return err
+			}
+			g.int64(1)
+			g.push(nil)
+			if err := g.expr(stmt.program); err != nil {
+				return err
+			}
+			g.ops(5, vm.OP_CHECKOUTPUT)
+
+		case *defineStatement:
+			if err := g.expr(stmt.expr); err != nil {
+				return err
+			}
+			g.stack[len(g.stack)-1] = stmt.variable
+			continue
+
+		default:
+			continue
+		}
+
+		if i != result {
+			g.ops(1, vm.OP_VERIFY)
+			g.stack = g.stack[:len(g.stack)-1]
+		}
+	}
+
+	if result < 0 {
+		g.op(vm.OP_TRUE)
+	}
+	return nil
+}
+
+// flush returns the code emitted since the last flush
+func (g *generator) flush() []byte {
+	code := g.code
+	g.code, g.last = nil, -1
+	return code
+}
+
+// emit appends the instruction to code, two adjacent SWAPs cancel out
+func (g *generator) emit(inst []byte) {
+	if len(inst) == 1 && vm.Op(inst[0]) == vm.OP_SWAP && g.last >= 0 && g.last == len(g.code)-1 && vm.Op(g.code[g.last]) == vm.OP_SWAP {
+		g.code, g.last = g.code[:g.last], -1
+		return
+	}
+	g.last = len(g.code)
+	g.code = append(g.code, inst...)
+}
+
+func (g *generator) op(op vm.Op) {
+	g.emit([]byte{byte(op)})
+}
+
+func (g *generator) int64(n int64) {
+	g.emit(vm.PushdataInt64(n))
+}
+
+func (g *generator) data(data []byte) {
+	g.emit(vm.PushdataBytes(data))
+}
+
+func (g *generator) push(v *binding) {
+	g.stack = append(g.stack, v)
+}
+
+// ops emits the ops which pop n values and push the result
+func (g *generator) ops(n int, ops ...vm.Op) {
+	for _, op := range ops {
+		g.op(op)
+	}
+	g.stack = append(g.stack[:len(g.stack)-n], nil)
+}
+
+// roll emits the ops moving the value at depth to the top of stack
+func (g *generator) roll(depth int) {
+	switch depth {
+	case 0:
+	case 1:
+		g.op(vm.OP_SWAP)
+	case 2:
+		g.op(vm.OP_ROT)
+	default:
+		g.int64(int64(depth))
+		g.op(vm.OP_ROLL)
+	}
+}
+
+// pick emits the ops copying the value at depth to the top of stack
+func (g *generator) pick(depth int) {
+	switch depth {
+	case 0:
+		g.op(vm.OP_DUP)
+	case 1:
+		g.op(vm.OP_OVER)
+	default:
+		g.int64(int64(depth))
+		g.op(vm.OP_PICK)
+	}
+}
+
+func (g *generator) ref(v *binding) error {
+	i := len(g.stack) - 1
+	for ; i >= 0 && g.stack[i] != v; i-- {
+	}
+	if i < 0 {
+		return errors.WithDetailf(ErrCompile, "%s is not on the stack", v.name)
+	}
+
+	depth := len(g.stack) - 1 - i
+	if g.counts[v]--; g.counts[v] > 0 {
+		g.pick(depth)
+	} else {
+		g.roll(depth)
+		g.stack = append(g.stack[:i], g.stack[i+1:]...)
+	}
+	g.push(nil)
+	return nil
+}
+
+func (g *generator) expr(e expression) error {
+	switch e := e.(type) {
+	case *binaryExpr:
+		if err := g.expr(e.left); err != nil {
+			return err
+		}
+		if err := g.expr(e.right); err != nil {
+			return err
+		}
+
+		ops := e.op.ops
+		if len(e.op.numOps) > 0 && isNumericType(e.left.typ()) && isNumericType(e.right.typ()) {
+			ops = e.op.numOps
+		}
+		g.ops(2, ops...)
+
+	case *unaryExpr:
+		if err := g.expr(e.expr); err != nil {
+			return err
+		}
+		g.ops(1, e.op.ops...)
+
+	case *callExpr:
+		return g.call(e)
+
+	case *varRef:
+		return g.ref(e.variable)
+
+	case *valueRef:
+		if e.t == amountType {
+			g.op(vm.OP_AMOUNT)
+		} else {
+			g.op(vm.OP_ASSET)
+		}
+		g.push(nil)
+
+	case integerLiteral:
+		g.int64(int64(e))
+		g.push(nil)
+
+	case bytesLiteral:
+		g.data(e)
+		g.push(nil)
+
+	case stringLiteral:
+		g.data([]byte(e))
+		g.push(nil)
+
+	case booleanLiteral:
+		if e {
+			g.op(vm.OP_TRUE)
+		} else {
+			g.op(vm.OP_FALSE)
+		}
+		g.push(nil)
+
+	default:
+		return errors.WithDetailf(ErrCompile, "unexpected expression %s", e)
+	}
+	return nil
+}
+
+func (g *generator) call(e *callExpr) error {
+	if e.builtin.name == "checkTxMultiSig" {
+		// [... sigs... <txsighash> pubkeys... <nsigs> <npubkeys>]
+		pubkeys, sigs := e.args[0].(*listExpr).items, e.args[1].(*listExpr).items
+		for _, sig := range sigs {
+			if err := g.expr(sig); err != nil {
+				return err
+			}
+		}
+		g.op(vm.OP_TXSIGHASH)
+		g.push(nil)
+		for _, pubkey := range pubkeys {
+			if err := g.expr(pubkey); err != nil {
+				return err
+			}
+		}
+		g.int64(int64(len(sigs)))
+		g.int64(int64(len(pubkeys)))
+		g.stack = append(g.stack, nil, nil)
+		g.ops(len(sigs)+len(pubkeys)+3, e.builtin.ops...)
+		return nil
+	}
+
+	for i := range e.args {
+		arg := e.args[i]
+		if e.builtin.reverseArgs {
+			arg = e.args[len(e.args)-1-i]
+		}
+		if err := g.expr(arg); err != nil {
+			return err
+		}
+	}
+	g.ops(len(e.args), e.builtin.ops...)
+	return nil
+}
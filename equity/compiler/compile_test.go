@@ -0,0 +1,245 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	bctypes "github.com/bytom-community/wasm/bytom/protocol/bc/types"
+	"github.com/bytom-community/wasm/bytom/protocol/validation"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+)
+
+func mustCompile(t *testing.T, src string) *Contract {
+	contracts, err := Compile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contracts) != 1 {
+		t.Fatalf("got %d contracts, want 1", len(contracts))
+	}
+	return contracts[0]
+}
+
+// execute runs the clause of the contract instantiated with contractArgs as
+// the only input of a transaction, clauseArgs gets the signature hash of the
+// input to sign
+func execute(t *testing.T, contract *Contract, contractArgs [][]byte, clause string, clauseArgs func(sigHash bc.Hash) [][]byte) error {
+	program, err := contract.Instantiate(contractArgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txData := &bctypes.TxData{
+		Version: 1,
+		Inputs:  []*bctypes.TxInput{bctypes.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 10000, 0, program)},
+		Outputs: []*bctypes.TxOutput{bctypes.NewTxOutput(*consensus.BTMAssetID, 9000, []byte{byte(vm.OP_TRUE)})},
+	}
+	args, err := contract.ClauseArguments(clause, clauseArgs(bctypes.MapTx(txData).SigHash(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	context, err := validation.InputContext(txData, 0, args, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = vm.Verify(context, 100000)
+	return err
+}
+
+func integerArgs(ns ...int64) func(bc.Hash) [][]byte {
+	return func(bc.Hash) [][]byte {
+		var args [][]byte
+		for _, n := range ns {
+			args = append(args, vm.Int64Bytes(n))
+		}
+		return args
+	}
+}
+
+func TestClauseDispatch(t *testing.T) {
+	contract := mustCompile(t, `
+contract Dispatch(n: Integer) locks valueAmount of valueAsset {
+  clause zero(x: Integer) {
+    verify x == n
+    unlock valueAmount of valueAsset
+  }
+  clause one(x: Integer) {
+    verify x == n + 1
+    unlock valueAmount of valueAsset
+  }
+  clause two(x: Integer) {
+    verify x == n + 2
+    unlock valueAmount of valueAsset
+  }
+  clause three(x: Integer) {
+    verify x == n + 3
+    unlock valueAmount of valueAsset
+  }
+}`)
+
+	const n = 10
+	for i, clause := range contract.Clauses {
+		if clause.Selector != int64(i) {
+			t.Errorf("clause %s: got selector %d, want %d", clause.Name, clause.Selector, i)
+		}
+		for x := int64(n); x < n+int64(len(contract.Clauses)); x++ {
+			err := execute(t, contract, [][]byte{vm.Int64Bytes(n)}, clause.Name, integerArgs(x))
+			if want := x == n+int64(i); (err == nil) != want {
+				t.Errorf("clause %s with x %d: got error %v, want valid %v", clause.Name, x, err, want)
+			}
+		}
+	}
+}
+
+func TestDefineShadowing(t *testing.T) {
+	cases := []struct {
+		desc  string
+		src   string
+		x     int64
+		valid bool
+	}{
+		{
+			desc:  "shadow contract parameter",
+			src:   "define n: Integer = n + 1 define n: Integer = n * 2 verify x == n",
+			x:     8,
+			valid: true,
+		},
+		{
+			desc: "shadowed contract parameter is not referenced",
+			src:  "define n: Integer = n + 1 define n: Integer = n * 2 verify x == n",
+			x:    3,
+		},
+		{
+			desc:  "shadow clause parameter",
+			src:   "define x: Integer = x - 1 verify x == n",
+			x:     4,
+			valid: true,
+		},
+		{
+			desc: "shadowed clause parameter is not referenced",
+			src:  "define x: Integer = x - 1 verify x == n",
+			x:    3,
+		},
+		{
+			desc:  "shadowing keeps earlier references",
+			src:   "define m: Integer = n + x define n: Integer = x verify m == n + 3",
+			x:     5,
+			valid: true,
+		},
+		{
+			desc:  "define after verify",
+			src:   "verify x > n define x: Integer = 0",
+			x:     4,
+			valid: true,
+		},
+		{
+			desc: "define after failed verify",
+			src:  "verify x > n define x: Integer = 0",
+			x:    3,
+		},
+	}
+
+	for _, c := range cases {
+		contract := mustCompile(t, `
+contract Shadow(n: Integer) locks valueAmount of valueAsset {
+  clause spend(x: Integer) {
+    `+c.src+`
+    unlock valueAmount of valueAsset
+  }
+}`)
+		if err := execute(t, contract, [][]byte{vm.Int64Bytes(3)}, "spend", integerArgs(c.x)); (err == nil) != c.valid {
+			t.Errorf("%s: got error %v, want valid %v", c.desc, err, c.valid)
+		}
+	}
+}
+
+func TestCheckTxSig(t *testing.T) {
+	contract := mustCompile(t, `
+contract Sig(pk: PublicKey) locks valueAmount of valueAsset {
+  clause spend(sig: Signature) {
+    verify checkTxSig(pk, sig)
+    unlock valueAmount of valueAsset
+  }
+}`)
+
+	xprv, xpub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherXPrv, _, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		desc  string
+		sign  func(bc.Hash) [][]byte
+		valid bool
+	}{
+		{
+			desc:  "signed by key",
+			sign:  func(h bc.Hash) [][]byte { return [][]byte{xprv.Sign(h.Bytes())} },
+			valid: true,
+		},
+		{
+			desc: "signed by other key",
+			sign: func(h bc.Hash) [][]byte { return [][]byte{otherXPrv.Sign(h.Bytes())} },
+		},
+		{
+			desc: "signed other hash",
+			sign: func(bc.Hash) [][]byte { return [][]byte{xprv.Sign(make([]byte, 32))} },
+		},
+	}
+
+	for _, c := range cases {
+		if err := execute(t, contract, [][]byte{xpub.PublicKey()}, "spend", c.sign); (err == nil) != c.valid {
+			t.Errorf("%s: got error %v, want valid %v", c.desc, err, c.valid)
+		}
+	}
+}
+
+func TestCheckTxMultiSig(t *testing.T) {
+	contract := mustCompile(t, `
+contract MultiSig(a: PublicKey, b: PublicKey, c: PublicKey) locks valueAmount of valueAsset {
+  clause spend(sig1: Signature, sig2: Signature) {
+    verify checkTxMultiSig([a, b, c], [sig1, sig2])
+    unlock valueAmount of valueAsset
+  }
+}`)
+
+	var (
+		xprvs   []chainkd.XPrv
+		pubkeys [][]byte
+	)
+	for i := 0; i < 3; i++ {
+		xprv, xpub, err := chainkd.NewXKeys(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xprvs, pubkeys = append(xprvs, xprv), append(pubkeys, xpub.PublicKey())
+	}
+
+	cases := []struct {
+		signers []int
+		valid   bool
+	}{
+		{signers: []int{0, 1}, valid: true},
+		{signers: []int{0, 2}, valid: true},
+		{signers: []int{1, 2}, valid: true},
+		{signers: []int{1, 0}},
+		{signers: []int{2, 0}},
+		{signers: []int{0, 0}},
+	}
+
+	for _, c := range cases {
+		sign := func(h bc.Hash) [][]byte {
+			return [][]byte{xprvs[c.signers[0]].Sign(h.Bytes()), xprvs[c.signers[1]].Sign(h.Bytes())}
+		}
+		if err := execute(t, contract, pubkeys, "spend", sign); (err == nil) != c.valid {
+			t.Errorf("signers %v: got error %v, want valid %v", c.signers, err, c.valid)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package compiler
+
+import (
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+)
+
+var (
+	// ErrBadArgument is returned when the argument does not match the parameter
+	ErrBadArgument = errors.New("bad contract argument")
+	// ErrClauseNotFound is returned when the contract has no clause of the name
+	ErrClauseNotFound = errors.New("clause not found")
+)
+
+// Instantiate returns the control program of the contract with the
+// contract arguments, which are converted into bytes by
+// ConvertContractArg. The program pushes the arguments in reverse order
+// and runs the contract body by CHECKPREDICATE:
+//
+//	<argN> ... <arg1> DEPTH <body> FALSE CHECKPREDICATE
+func (c *Contract) Instantiate(args [][]byte) ([]byte, error) {
+	if err := checkArgs(c.Params, args); err != nil {
+		return nil, err
+	}
+
+	b := vmutil.NewBuilder()
+	for i := len(args) - 1; i >= 0; i-- {
+		if isNumericType(c.Params[i].Type) {
+			n, _ := vm.AsInt64(args[i])
+			b.AddInt64(n)
+		} else {
+			b.AddData(args[i])
+		}
+	}
+	b.AddOp(vm.OP_DEPTH)
+	b.AddData(c.Body)
+	b.AddOp(vm.OP_FALSE)
+	b.AddOp(vm.OP_CHECKPREDICATE)
+	return b.Build()
+}
+
+// ClauseArguments returns the witness arguments unlocking the clause of
+// the contract. The clause arguments are in reverse order, followed by
+// the clause selector when the contract has more than one clause.
+func (c *Contract) ClauseArguments(clauseName string, args [][]byte) ([][]byte, error) {
+	var clause *Clause
+	for _, cl := range c.Clauses {
+		if cl.Name == clauseName {
+			clause = cl
+			break
+		}
+	}
+	if clause == nil {
+		return nil, errors.WithDetailf(ErrClauseNotFound, "contract %s has no clause %s", c.Name, clauseName)
+	}
+
+	if err := checkArgs(clause.Params, args); err != nil {
+		return nil, err
+	}
+
+	witness := make([][]byte, 0, len(args)+1)
+	for i := len(args) - 1; i >= 0; i-- {
+		witness = append(witness, args[i])
+	}
+	if len(c.Clauses) > 1 {
+		witness = append(witness, vm.Int64Bytes(clause.Selector))
+	}
+	return witness, nil
+}
+
+func checkArgs(params []*Param, args [][]byte) error {
+	if len(args) != len(params) {
+		return errors.WithDetailf(ErrBadArgument, "%d arguments for %d parameters", len(args), len(params))
+	}
+
+	for i, param := range params {
+		if err := checkArg(param, args[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkArg checks the argument has the right form of the parameter type
+func checkArg(param *Param, arg []byte) error {
+	switch t := param.Type; {
+	case isNumericType(t):
+		n, err := vm.AsInt64(arg)
+		if err != nil {
+			return errors.WithDetailf(ErrBadArgument, "%s is not an integer", param.Name)
+		}
+		if t == amountType && n < 0 {
+			return errors.WithDetailf(ErrBadArgument, "%s is a negative amount", param.Name)
+		}
+
+	case t == assetType || t == publicKeyType || isHashType(t):
+		if len(arg) != 32 {
+			return errors.WithDetailf(ErrBadArgument, "%s of type %s has %d bytes, expected 32", param.Name, t, len(arg))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,761 @@
+package compiler
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
+var (
+	// ErrParse is returned when the Equity source can not be parsed
+	ErrParse = errors.New("equity parse error")
+	// ErrCompile is returned when the Equity contract is not valid
+	ErrCompile = errors.New("equity compile error")
+)
+
+var keywords = map[string]bool{
+	"contract": true,
+	"clause":   true,
+	"locks":    true,
+	"of":       true,
+	"verify":   true,
+	"lock":     true,
+	"unlock":   true,
+	"with":     true,
+	"define":   true,
+	"true":     true,
+	"false":    true,
+}
+
+// puncts is ordered so that the longer punctuation is matched first
+var puncts = []string{
+	"||", "&&", "==", "!=", ">=", "<=", "<<", ">>",
+	"(", ")", "{", "}", "[", "]", ",", ":", "=",
+	"+", "-", "*", "/", "%", "&", "|", "^", "~", "!", "<", ">",
+}
+
+type position struct {
+	line, col int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%d:%d", p.line, p.col)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokInt
+	tokHex
+	tokString
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  position
+}
+
+func lex(src string) ([]token, error) {
+	var (
+		toks []token
+		line = 1
+		col  = 1
+	)
+
+	advance := func(n int) {
+		for _, r := range src[:n] {
+			if r == '\n' {
+				line, col = line+1, 1
+			} else {
+				col++
+			}
+		}
+		src = src[n:]
+	}
+
+	for {
+		trimmed := strings.TrimLeftFunc(src, unicode.IsSpace)
+		advance(len(src) - len(trimmed))
+		if strings.HasPrefix(src, "//") {
+			end := strings.IndexByte(src, '\n')
+			if end < 0 {
+				end = len(src)
+			}
+			advance(end)
+			continue
+		}
+
+		pos := position{line, col}
+		if src == "" {
+			return append(toks, token{kind: tokEOF, pos: pos}), nil
+		}
+
+		var (
+			kind tokenKind
+			n    int
+		)
+		switch r := rune(src[0]); {
+		case strings.HasPrefix(src, "0x"):
+			kind, n = tokHex, 2+strings.IndexFunc(src[2:]+" ", func(r rune) bool { return !strings.ContainsRune("0123456789abcdefABCDEF", r) })
+
+		case unicode.IsDigit(r):
+			kind, n = tokInt, strings.IndexFunc(src+" ", func(r rune) bool { return !unicode.IsDigit(r) })
+
+		case r == '_' || unicode.IsLetter(r):
+			kind, n = tokIdent, strings.IndexFunc(src+" ", func(r rune) bool { return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+
+		case r == '"':
+			prefix, err := strconv.QuotedPrefix(src)
+			if err != nil {
+				return nil, errors.WithDetailf(ErrParse, "%s: bad string literal", pos)
+			}
+			kind, n = tokString, len(prefix)
+
+		default:
+			for _, p := range puncts {
+				if strings.HasPrefix(src, p) {
+					kind, n = tokPunct, len(p)
+					break
+				}
+			}
+			if n == 0 {
+				return nil, errors.WithDetailf(ErrParse, "%s: unexpected character %q", pos, r)
+			}
+		}
+
+		toks = append(toks, token{kind: kind, text: src[:n], pos: pos})
+		advance(n)
+	}
+}
+
+type parser struct {
+	toks []token
+	i    int
+
+	// scope is the list of names visible to the current statement, the
+	// later binding shadows the earlier one with the same name
+	scope       []*binding
+	amountName  string
+	assetName   string
+	clause      *Clause
+	outputIndex int64
+}
+
+func parse(src string) ([]*Contract, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{toks: toks}
+	var contracts []*Contract
+	for p.peek().kind != tokEOF {
+		contract, err := p.parseContract()
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, contract)
+	}
+	if len(contracts) == 0 {
+		return nil, errors.WithDetail(ErrParse, "no contract found")
+	}
+	return contracts, nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.toks[p.i]
+	if tok.kind != tokEOF {
+		p.i++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is the given keyword or punctuation
+func (p *parser) accept(text string) bool {
+	if tok := p.peek(); (tok.kind == tokIdent || tok.kind == tokPunct) && tok.text == text {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected(fmt.Sprintf("%q", text))
+	}
+	return nil
+}
+
+func (p *parser) expectIdent() (token, error) {
+	tok := p.peek()
+	if tok.kind != tokIdent || keywords[tok.text] {
+		return tok, p.unexpected("identifier")
+	}
+	return p.next(), nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	tok := p.peek()
+	if tok.kind == tokEOF {
+		return errors.WithDetailf(ErrParse, "%s: expected %s, got end of source", tok.pos, expected)
+	}
+	return errors.WithDetailf(ErrParse, "%s: expected %s, got %q", tok.pos, expected, tok.text)
+}
+
+func compileErrorf(pos position, format string, v ...interface{}) error {
+	return errors.WithDetailf(ErrCompile, "%s: %s", pos, fmt.Sprintf(format, v...))
+}
+
+func (p *parser) lookup(name string) *binding {
+	for i := len(p.scope) - 1; i >= 0; i-- {
+		if p.scope[i].name == name {
+			return p.scope[i]
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseContract() (*Contract, error) {
+	if err := p.expect("contract"); err != nil {
+		return nil, err
+	}
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := p.parseParams()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("locks"); err != nil {
+		return nil, err
+	}
+	amount, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("of"); err != nil {
+		return nil, err
+	}
+	asset, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	contract := &Contract{
+		Name:  name.text,
+		Value: ValueInfo{Amount: amount.text, Asset: asset.text},
+	}
+	p.scope, p.amountName, p.assetName = nil, amount.text, asset.text
+	if amount.text == asset.text {
+		return nil, compileErrorf(asset.pos, "value amount and asset have the same name %s", asset.text)
+	}
+	for _, param := range params {
+		if err := p.declare(param.pos, param.variable); err != nil {
+			return nil, err
+		}
+		contract.params = append(contract.params, param.variable)
+		contract.Params = append(contract.Params, newParam(param.variable))
+	}
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.accept("}") {
+		clause, err := p.parseClause()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range contract.Clauses {
+			if c.Name == clause.Name {
+				return nil, compileErrorf(name.pos, "clause %s is declared twice", clause.Name)
+			}
+		}
+		clause.Selector = int64(len(contract.Clauses))
+		contract.Clauses = append(contract.Clauses, clause)
+		p.scope = p.scope[:len(contract.params)]
+	}
+	if len(contract.Clauses) == 0 {
+		return nil, compileErrorf(name.pos, "contract %s has no clause", contract.Name)
+	}
+	return contract, nil
+}
+
+type paramDecl struct {
+	pos      position
+	variable *binding
+}
+
+// parseParams parses the parameter list like (a, b: Integer, c: Asset)
+func (p *parser) parseParams() ([]*paramDecl, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var params, untyped []*paramDecl
+	for !p.accept(")") {
+		if len(params)+len(untyped) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		name, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		untyped = append(untyped, &paramDecl{pos: name.pos, variable: &binding{name: name.text}})
+		if !p.accept(":") {
+			continue
+		}
+
+		t, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		for _, param := range untyped {
+			param.variable.t = t
+		}
+		params, untyped = append(params, untyped...), nil
+	}
+	if len(untyped) > 0 {
+		return nil, errors.WithDetailf(ErrParse, "%s: parameter %s has no type", untyped[0].pos, untyped[0].variable.name)
+	}
+	return params, nil
+}
+
+func (p *parser) parseType() (typeDesc, error) {
+	tok, err := p.expectIdent()
+	if err != nil {
+		return "", err
+	}
+
+	t := tok.text
+	if p.accept("(") {
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect(")"); err != nil {
+			return "", err
+		}
+		t += "(" + string(inner) + ")"
+	}
+	if !validType(typeDesc(t)) {
+		return "", compileErrorf(tok.pos, "unknown type %s", t)
+	}
+	return typeDesc(t), nil
+}
+
+// declare adds the contract or clause parameter into scope
+func (p *parser) declare(pos position, variable *binding) error {
+	if variable.name == p.amountName || variable.name == p.assetName || lookupBuiltin(variable.name) != nil || p.lookup(variable.name) != nil {
+		return compileErrorf(pos, "name %s is already declared", variable.name)
+	}
+	p.scope = append(p.scope, variable)
+	return nil
+}
+
+func newParam(variable *binding) *Param {
+	return &Param{Name: variable.name, Type: variable.t, ArgType: argType(variable.t)}
+}
+
+func (p *parser) parseClause() (*Clause, error) {
+	if err := p.expect("clause"); err != nil {
+		return nil, err
+	}
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	params, err := p.parseParams()
+	if err != nil {
+		return nil, err
+	}
+
+	clause := &Clause{Name: name.text, Params: []*Param{}, Values: []ValueInfo{}}
+	for _, param := range params {
+		if err := p.declare(param.pos, param.variable); err != nil {
+			return nil, err
+		}
+		clause.params = append(clause.params, param.variable)
+		clause.Params = append(clause.Params, newParam(param.variable))
+	}
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	p.clause, p.outputIndex = clause, 0
+	disposed := false
+	for !p.accept("}") {
+		stmt, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+
+		switch stmt := stmt.(type) {
+		case *lockStatement:
+			disposed = disposed || p.isContractAsset(stmt.asset)
+			clause.Values = append(clause.Values, ValueInfo{Program: stmt.program.String(), Asset: stmt.asset.String(), Amount: stmt.amount.String()})
+
+		case *unlockStatement:
+			if !p.isContractAsset(stmt.asset) {
+				return nil, compileErrorf(stmt.pos, "clause %s unlocks %s which is not the contract value", clause.Name, stmt.asset)
+			}
+			disposed = true
+			clause.Values = append(clause.Values, ValueInfo{Asset: stmt.asset.String(), Amount: stmt.amount.String()})
+		}
+		clause.statements = append(clause.statements, stmt)
+	}
+	if !disposed {
+		return nil, compileErrorf(name.pos, "clause %s does not lock or unlock the contract value", clause.Name)
+	}
+	return clause, nil
+}
+
+func (p *parser) isContractAsset(e expression) bool {
+	ref, ok := e.(*valueRef)
+	return ok && ref.name == p.assetName
+}
+
+func (p *parser) parseStatement() (statement, error) {
+	tok := p.peek()
+	switch {
+	case p.accept("verify"):
+		expr, err := p.parseExpr(1)
+		if err != nil {
+			return nil, err
+		}
+		if expr.typ() != booleanType {
+			return nil, compileErrorf(tok.pos, "verify expression %s has type %s, expected Boolean", expr, expr.typ())
+		}
+		return &verifyStatement{pos: tok.pos, expr: expr}, nil
+
+	case p.accept("lock"):
+		amount, asset, err := p.parseValue(tok.pos)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("with"); err != nil {
+			return nil, err
+		}
+		program, err := p.parseExpr(1)
+		if err != nil {
+			return nil, err
+		}
+		if !assignable(program.typ(), programType) {
+			return nil, compileErrorf(tok.pos, "lock program %s has type %s, expected Program", program, program.typ())
+		}
+
+		stmt := &lockStatement{pos: tok.pos, amount: amount, asset: asset, program: program, index: p.outputIndex}
+		p.outputIndex++
+		return stmt, nil
+
+	case p.accept("unlock"):
+		amount, asset, err := p.parseValue(tok.pos)
+		if err != nil {
+			return nil, err
+		}
+		return &unlockStatement{pos: tok.pos, amount: amount, asset: asset}, nil
+
+	case p.accept("define"):
+		name, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		if name.text == p.amountName || name.text == p.assetName || lookupBuiltin(name.text) != nil {
+			return nil, compileErrorf(name.pos, "name %s is reserved", name.text)
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		t, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseExpr(1)
+		if err != nil {
+			return nil, err
+		}
+		if !assignable(expr.typ(), t) {
+			return nil, compileErrorf(name.pos, "%s has type %s, can not be defined as %s", expr, expr.typ(), t)
+		}
+
+		variable := &binding{name: name.text, t: t}
+		p.scope = append(p.scope, variable)
+		return &defineStatement{pos: tok.pos, variable: variable, expr: expr}, nil
+	}
+	return nil, p.unexpected("statement")
+}
+
+// parseValue parses the "amount of asset" of lock and unlock statement
+func (p *parser) parseValue(pos position) (expression, expression, error) {
+	amount, err := p.parseExpr(1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := p.expect("of"); err != nil {
+		return nil, nil, err
+	}
+	asset, err := p.parseExpr(1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !isNumericType(amount.typ()) {
+		return nil, nil, compileErrorf(pos, "amount %s has type %s, expected Amount", amount, amount.typ())
+	}
+	if !assignable(asset.typ(), assetType) {
+		return nil, nil, compileErrorf(pos, "asset %s has type %s, expected Asset", asset, asset.typ())
+	}
+	return amount, asset, nil
+}
+
+func (p *parser) binaryOp() *binaryOp {
+	tok := p.peek()
+	if tok.kind != tokPunct {
+		return nil
+	}
+	for _, op := range binaryOps {
+		if op.op == tok.text {
+			return op
+		}
+	}
+	return nil
+}
+
+// parseExpr parses the expression whose binary operators have precedence
+// no less than minPrecedence
+func (p *parser) parseExpr(minPrecedence int) (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.binaryOp()
+		if op == nil || op.precedence < minPrecedence {
+			return left, nil
+		}
+
+		tok := p.next()
+		right, err := p.parseExpr(op.precedence + 1)
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newBinaryExpr(tok.pos, op, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func newBinaryExpr(pos position, op *binaryOp, left, right expression) (expression, error) {
+	if op.left == anyType {
+		if !compatible(left.typ(), right.typ()) || left.typ() == listType {
+			return nil, compileErrorf(pos, "can not compare %s of type %s with %s of type %s", left, left.typ(), right, right.typ())
+		}
+	} else if !assignable(left.typ(), op.left) || !assignable(right.typ(), op.right) {
+		return nil, compileErrorf(pos, "operator %s expects %s and %s, got %s and %s", op.op, op.left, op.right, left.typ(), right.typ())
+	}
+	return &binaryExpr{pos: pos, left: left, right: right, op: op, t: op.result}, nil
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	tok := p.peek()
+	if tok.kind == tokPunct {
+		for _, op := range unaryOps {
+			if op.op != tok.text {
+				continue
+			}
+
+			p.next()
+			if op.op == "-" && p.peek().kind == tokInt {
+				n, err := strconv.ParseInt("-"+p.next().text, 10, 64)
+				if err != nil {
+					return nil, compileErrorf(tok.pos, "bad integer literal: %v", err)
+				}
+				return integerLiteral(n), nil
+			}
+
+			expr, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			if !assignable(expr.typ(), op.operand) {
+				return nil, compileErrorf(tok.pos, "operator %s expects %s, got %s", op.op, op.operand, expr.typ())
+			}
+			return &unaryExpr{pos: tok.pos, op: op, expr: expr}, nil
+		}
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expression, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokInt:
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, compileErrorf(tok.pos, "bad integer literal: %v", err)
+		}
+		return integerLiteral(n), nil
+
+	case tokHex:
+		b, err := hex.DecodeString(tok.text[2:])
+		if err != nil {
+			return nil, compileErrorf(tok.pos, "bad hex literal: %v", err)
+		}
+		return bytesLiteral(b), nil
+
+	case tokString:
+		s, err := strconv.Unquote(tok.text)
+		if err != nil {
+			return nil, compileErrorf(tok.pos, "bad string literal: %v", err)
+		}
+		return stringLiteral(s), nil
+
+	case tokPunct:
+		switch tok.text {
+		case "(":
+			expr, err := p.parseExpr(1)
+			if err != nil {
+				return nil, err
+			}
+			return expr, p.expect(")")
+
+		case "[":
+			list := &listExpr{pos: tok.pos}
+			for !p.accept("]") {
+				if len(list.items) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				item, err := p.parseExpr(1)
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+			}
+			return list, nil
+		}
+
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return booleanLiteral(true), nil
+		case "false":
+			return booleanLiteral(false), nil
+		case p.amountName:
+			return &valueRef{pos: tok.pos, name: tok.text, t: amountType}, nil
+		case p.assetName:
+			return &valueRef{pos: tok.pos, name: tok.text, t: assetType}, nil
+		}
+
+		if p.peek().text == "(" && p.peek().kind == tokPunct {
+			return p.parseCall(tok)
+		}
+		if keywords[tok.text] {
+			break
+		}
+
+		variable := p.lookup(tok.text)
+		if variable == nil {
+			return nil, compileErrorf(tok.pos, "undefined name %s", tok.text)
+		}
+		return &varRef{pos: tok.pos, name: tok.text, variable: variable}, nil
+	}
+
+	p.i--
+	return nil, p.unexpected("expression")
+}
+
+func (p *parser) parseCall(name token) (expression, error) {
+	b := lookupBuiltin(name.text)
+	if b == nil {
+		return nil, compileErrorf(name.pos, "unknown function %s", name.text)
+	}
+
+	p.next()
+	call := &callExpr{pos: name.pos, builtin: b}
+	for !p.accept(")") {
+		if len(call.args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseExpr(1)
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+	}
+	if len(call.args) != len(b.args) {
+		return nil, compileErrorf(name.pos, "%s expects %d arguments, got %d", b.name, len(b.args), len(call.args))
+	}
+
+	for i, arg := range call.args {
+		switch want := b.args[i]; {
+		case want == anyType:
+			if arg.typ() == listType {
+				return nil, compileErrorf(name.pos, "%s can not take a list argument", b.name)
+			}
+
+		case want == listType:
+			if err := checkListArg(name.pos, arg, []typeDesc{publicKeyType, signatureType}[i]); err != nil {
+				return nil, err
+			}
+
+		case !assignable(arg.typ(), want):
+			return nil, compileErrorf(name.pos, "argument %d of %s has type %s, expected %s", i+1, b.name, arg.typ(), want)
+		}
+	}
+
+	if b.name == "checkTxMultiSig" {
+		nkeys, nsigs := len(call.args[0].(*listExpr).items), len(call.args[1].(*listExpr).items)
+		if nsigs == 0 || nsigs > nkeys {
+			return nil, compileErrorf(name.pos, "checkTxMultiSig expects 1 to %d signatures, got %d", nkeys, nsigs)
+		}
+	}
+
+	if p.clause != nil {
+		switch b.name {
+		case "above", "below":
+			p.clause.BlockHeight = append(p.clause.BlockHeight, call.args[0].String())
+		case "sha3", "sha256":
+			p.clause.HashCalls = append(p.clause.HashCalls, HashCall{HashType: b.name, Arg: call.args[0].String(), ArgType: string(call.args[0].typ())})
+		}
+	}
+	return call, nil
+}
+
+// checkListArg checks the list argument of checkTxMultiSig
+func checkListArg(pos position, arg expression, itemType typeDesc) error {
+	list, ok := arg.(*listExpr)
+	if !ok {
+		return compileErrorf(pos, "checkTxMultiSig expects list of %s, got %s", itemType, arg.typ())
+	}
+	for _, item := range list.items {
+		if !assignable(item.typ(), itemType) {
+			return compileErrorf(pos, "checkTxMultiSig expects list of %s, got %s of type %s", itemType, item, item.typ())
+		}
+	}
+	return nil
+}
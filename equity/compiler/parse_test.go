@@ -0,0 +1,131 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/errors"
+)
+
+func TestLex(t *testing.T) {
+	cases := []struct {
+		src   string
+		kinds []tokenKind
+		texts []string
+		pos   []position
+	}{
+		{
+			src:   "contract C(a: Integer)",
+			kinds: []tokenKind{tokIdent, tokIdent, tokPunct, tokIdent, tokPunct, tokIdent, tokPunct, tokEOF},
+			texts: []string{"contract", "C", "(", "a", ":", "Integer", ")", ""},
+		},
+		{
+			src:   "x >= 0x0aFF // comment\n  \"s\\\"q\" 12",
+			kinds: []tokenKind{tokIdent, tokPunct, tokHex, tokString, tokInt, tokEOF},
+			texts: []string{"x", ">=", "0x0aFF", `"s\"q"`, "12", ""},
+			pos:   []position{{1, 1}, {1, 3}, {1, 6}, {2, 3}, {2, 10}, {2, 12}},
+		},
+		{
+			src:   "a<<b<c||!d",
+			kinds: []tokenKind{tokIdent, tokPunct, tokIdent, tokPunct, tokIdent, tokPunct, tokPunct, tokIdent, tokEOF},
+			texts: []string{"a", "<<", "b", "<", "c", "||", "!", "d", ""},
+		},
+		{
+			src:   "_a1 b_2// end",
+			kinds: []tokenKind{tokIdent, tokIdent, tokEOF},
+			texts: []string{"_a1", "b_2", ""},
+		},
+	}
+
+	for i, c := range cases {
+		toks, err := lex(c.src)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if len(toks) != len(c.kinds) {
+			t.Fatalf("case %d: got %d tokens, want %d", i, len(toks), len(c.kinds))
+		}
+		for j, tok := range toks {
+			if tok.kind != c.kinds[j] || tok.text != c.texts[j] {
+				t.Errorf("case %d: token %d got (%d, %q), want (%d, %q)", i, j, tok.kind, tok.text, c.kinds[j], c.texts[j])
+			}
+			if c.pos != nil && tok.pos != c.pos[j] {
+				t.Errorf("case %d: token %d got position %s, want %s", i, j, tok.pos, c.pos[j])
+			}
+		}
+	}
+}
+
+func TestLexError(t *testing.T) {
+	for i, src := range []string{"a @ b", `"unterminated`, "a\n  $"} {
+		if _, err := lex(src); errors.Root(err) != ErrParse {
+			t.Errorf("case %d: got error %v, want %v", i, err, ErrParse)
+		}
+	}
+}
+
+// clauseSource returns the contract with a clause of the given parameters and statements
+func clauseSource(params, stmts string) string {
+	return `
+contract C(pk: PublicKey, n: Integer, prog: Program) locks valueAmount of valueAsset {
+  clause spend(` + params + `) {
+    ` + stmts + `
+    unlock valueAmount of valueAsset
+  }
+}`
+}
+
+func TestCompileError(t *testing.T) {
+	cases := []struct {
+		desc string
+		src  string
+		want error
+	}{
+		{desc: "verify integer", src: clauseSource("", "verify n + 1"), want: ErrCompile},
+		{desc: "lock with public key", src: clauseSource("", "lock valueAmount of valueAsset with pk"), want: ErrCompile},
+		{desc: "lock amount of public key", src: clauseSource("", "lock pk of valueAsset with prog"), want: ErrCompile},
+		{desc: "compare integer with public key", src: clauseSource("", "verify n == pk"), want: ErrCompile},
+		{desc: "compare list", src: clauseSource("", "verify [pk] == [pk]"), want: ErrCompile},
+		{desc: "add boolean", src: clauseSource("", "verify true + n > 0"), want: ErrCompile},
+		{desc: "not integer", src: clauseSource("", "verify !n"), want: ErrCompile},
+		{desc: "checkTxSig swapped arguments", src: clauseSource("sig: Signature", "verify checkTxSig(sig, pk)"), want: ErrCompile},
+		{desc: "checkTxSig argument count", src: clauseSource("sig: Signature", "verify checkTxSig(pk)"), want: ErrCompile},
+		{desc: "checkTxMultiSig keys not list", src: clauseSource("sig: Signature", "verify checkTxMultiSig(pk, [sig])"), want: ErrCompile},
+		{desc: "checkTxMultiSig swapped lists", src: clauseSource("sig: Signature", "verify checkTxMultiSig([sig], [pk])"), want: ErrCompile},
+		{desc: "checkTxMultiSig too many signatures", src: clauseSource("s1: Signature, s2: Signature", "verify checkTxMultiSig([pk], [s1, s2])"), want: ErrCompile},
+		{desc: "sha3 of list", src: clauseSource("", "verify sha3([pk]) == 0x00"), want: ErrCompile},
+		{desc: "define mismatched type", src: clauseSource("", "define x: Integer = pk"), want: ErrCompile},
+		{desc: "define reserved name", src: clauseSource("", "define valueAmount: Integer = n"), want: ErrCompile},
+		{desc: "define builtin name", src: clauseSource("", "define sha3: Integer = n"), want: ErrCompile},
+		{desc: "undefined name", src: clauseSource("", "verify m > 0"), want: ErrCompile},
+		{desc: "unknown function", src: clauseSource("", "verify foo(n)"), want: ErrCompile},
+		{desc: "unknown type", src: clauseSource("x: Number", "verify x > 0"), want: ErrCompile},
+		{desc: "unknown hash inner type", src: clauseSource("x: Sha3(Number)", "verify x == x"), want: ErrCompile},
+		{desc: "duplicated parameter", src: clauseSource("n: Integer", "verify n > 0"), want: ErrCompile},
+		{
+			desc: "unlock other asset",
+			src:  "contract C(a: Asset) locks valueAmount of valueAsset { clause spend() { unlock valueAmount of a } }",
+			want: ErrCompile,
+		},
+		{
+			desc: "clause without value",
+			src:  "contract C(n: Integer) locks valueAmount of valueAsset { clause spend() { verify n > 0 } }",
+			want: ErrCompile,
+		},
+		{
+			desc: "duplicated clause",
+			src:  "contract C() locks valueAmount of valueAsset { clause a() { unlock valueAmount of valueAsset } clause a() { unlock valueAmount of valueAsset } }",
+			want: ErrCompile,
+		},
+		{desc: "contract without clause", src: "contract C() locks valueAmount of valueAsset {}", want: ErrCompile},
+		{desc: "empty source", src: "// nothing", want: ErrParse},
+		{desc: "parameter without type", src: "contract C(a) locks valueAmount of valueAsset {}", want: ErrParse},
+		{desc: "missing parenthesis", src: clauseSource("", "verify (n > 0"), want: ErrParse},
+		{desc: "keyword as name", src: "contract lock() locks valueAmount of valueAsset {}", want: ErrParse},
+	}
+
+	for _, c := range cases {
+		if _, err := Compile(c.src); errors.Root(err) != c.want {
+			t.Errorf("%s: got error %v, want %v", c.desc, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package compiler
+
+import "strings"
+
+// typeDesc is the name of an Equity type
+type typeDesc string
+
+const (
+	amountType    typeDesc = "Amount"
+	assetType     typeDesc = "Asset"
+	booleanType   typeDesc = "Boolean"
+	hashType      typeDesc = "Hash"
+	integerType   typeDesc = "Integer"
+	programType   typeDesc = "Program"
+	publicKeyType typeDesc = "PublicKey"
+	signatureType typeDesc = "Signature"
+	stringType    typeDesc = "String"
+
+	// bytesType is the type of hex literal, which is compatible with
+	// any type represented by bytes
+	bytesType typeDesc = "Bytes"
+
+	// listType is the type of list literal, which is only allowed as
+	// the argument of checkTxMultiSig
+	listType typeDesc = "List"
+)
+
+var types = map[typeDesc]bool{
+	amountType:    true,
+	assetType:     true,
+	booleanType:   true,
+	hashType:      true,
+	integerType:   true,
+	programType:   true,
+	publicKeyType: true,
+	signatureType: true,
+	stringType:    true,
+}
+
+// hashOf returns the type of the hash of a value, such as Sha3(String)
+func hashOf(hashFunc string, t typeDesc) typeDesc {
+	name := "Sha3"
+	if hashFunc == "sha256" {
+		name = "Sha256"
+	}
+	return typeDesc(name + "(" + string(t) + ")")
+}
+
+func isHashType(t typeDesc) bool {
+	return t == hashType || strings.HasPrefix(string(t), "Sha3(") || strings.HasPrefix(string(t), "Sha256(")
+}
+
+func isNumericType(t typeDesc) bool {
+	return t == integerType || t == amountType
+}
+
+// isBytesType tells whether the values of type are compared as bytes
+func isBytesType(t typeDesc) bool {
+	return !isNumericType(t) && t != booleanType && t != listType
+}
+
+// validType checks the declared type, the hash type is allowed with a
+// valid inner type
+func validType(t typeDesc) bool {
+	if types[t] {
+		return true
+	}
+
+	s := string(t)
+	for _, prefix := range []string{"Sha3(", "Sha256("} {
+		if strings.HasPrefix(s, prefix) && strings.HasSuffix(s, ")") {
+			return validType(typeDesc(s[len(prefix) : len(s)-1]))
+		}
+	}
+	return false
+}
+
+// compatible tells whether values of the two types can be compared
+func compatible(t1, t2 typeDesc) bool {
+	switch {
+	case t1 == t2:
+		return true
+	case isNumericType(t1) && isNumericType(t2):
+		return true
+	case isHashType(t1) && isHashType(t2):
+		return true
+	case t1 == bytesType:
+		return isBytesType(t2)
+	case t2 == bytesType:
+		return isBytesType(t1)
+	}
+	return false
+}
+
+// assignable tells whether the value of type from can be used as type to
+func assignable(from, to typeDesc) bool {
+	if from == to || from == bytesType && isBytesType(to) {
+		return true
+	}
+	return isNumericType(from) && isNumericType(to) || isHashType(from) && isHashType(to)
+}
+
+// argType returns the argument type accepted by ConvertContractArg
+func argType(t typeDesc) string {
+	switch {
+	case isNumericType(t):
+		return "integer"
+	case t == booleanType:
+		return "boolean"
+	case t == stringType:
+		return "string"
+	default:
+		return "data"
+	}
+}
@@ -0,0 +1,92 @@
+package templates
+
+import "github.com/bytom-community/wasm/bytom/crypto/ed25519"
+
+// EscrowName is the template name of escrow contract
+const EscrowName = "Escrow"
+
+// escrowSource is the 2-of-3 escrow contract, any two of the buyer, the
+// seller and the arbiter release the value to the seller or refund it to
+// the buyer. The value is locked by the output 0 of the transaction.
+const escrowSource = `
+contract Escrow(buyer: PublicKey,
+                seller: PublicKey,
+                arbiter: PublicKey,
+                buyerProgram: Program,
+                sellerProgram: Program) locks valueAmount of valueAsset {
+  clause release(sig1: Signature, sig2: Signature) {
+    verify checkTxMultiSig([buyer, seller, arbiter], [sig1, sig2])
+    lock valueAmount of valueAsset with sellerProgram
+  }
+  clause refund(sig1: Signature, sig2: Signature) {
+    verify checkTxMultiSig([buyer, seller, arbiter], [sig1, sig2])
+    lock valueAmount of valueAsset with buyerProgram
+  }
+}
+`
+
+var escrowTemplate = newTemplate(EscrowName, escrowSource)
+
+// Escrow is the arguments of escrow contract
+type Escrow struct {
+	Buyer         ed25519.PublicKey
+	Seller        ed25519.PublicKey
+	Arbiter       ed25519.PublicKey
+	BuyerProgram  []byte
+	SellerProgram []byte
+}
+
+// Program returns the control program of the contract
+func (c *Escrow) Program() ([]byte, error) {
+	if err := checkPubkey("buyer", c.Buyer); err != nil {
+		return nil, err
+	}
+	if err := checkPubkey("seller", c.Seller); err != nil {
+		return nil, err
+	}
+	if err := checkPubkey("arbiter", c.Arbiter); err != nil {
+		return nil, err
+	}
+	if err := checkProgram("buyer program", c.BuyerProgram); err != nil {
+		return nil, err
+	}
+	if err := checkProgram("seller program", c.SellerProgram); err != nil {
+		return nil, err
+	}
+	return escrowTemplate.instantiate(c.Buyer, c.Seller, c.Arbiter, c.BuyerProgram, c.SellerProgram)
+}
+
+// ParseEscrow parses the program generated by Escrow
+func ParseEscrow(program []byte) (*Escrow, error) {
+	args, err := escrowTemplate.parse(program)
+	if err != nil {
+		return nil, err
+	}
+	return &Escrow{
+		Buyer:         ed25519.PublicKey(args[0]),
+		Seller:        ed25519.PublicKey(args[1]),
+		Arbiter:       ed25519.PublicKey(args[2]),
+		BuyerProgram:  args[3],
+		SellerProgram: args[4],
+	}, nil
+}
+
+// EscrowReleaseArguments returns the witness arguments releasing the
+// value to the seller, the signatures must be in the order of buyer,
+// seller and arbiter
+func EscrowReleaseArguments(sig1, sig2 []byte) ([][]byte, error) {
+	if err := checkSignatures(sig1, sig2); err != nil {
+		return nil, err
+	}
+	return escrowTemplate.clauseArguments("release", sig1, sig2)
+}
+
+// EscrowRefundArguments returns the witness arguments refunding the
+// value to the buyer, the signatures must be in the order of buyer,
+// seller and arbiter
+func EscrowRefundArguments(sig1, sig2 []byte) ([][]byte, error) {
+	if err := checkSignatures(sig1, sig2); err != nil {
+		return nil, err
+	}
+	return escrowTemplate.clauseArguments("refund", sig1, sig2)
+}
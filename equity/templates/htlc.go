@@ -0,0 +1,91 @@
+package templates
+
+import (
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+)
+
+// HTLCName is the template name of hash time-locked contract
+const HTLCName = "HTLC"
+
+// htlcSource is the hash time-locked contract, the recipient unlocks the
+// value by the preimage of hash, or the sender takes it back after the
+// block height of expiry. The hash is SHA-256 so that the contract can
+// be used in the atomic swap with other chains.
+const htlcSource = `
+contract HTLC(sender: PublicKey,
+              recipient: PublicKey,
+              expiry: Integer,
+              hash: Sha256(String)) locks valueAmount of valueAsset {
+  clause complete(preimage: String, sig: Signature) {
+    verify sha256(preimage) == hash
+    verify checkTxSig(recipient, sig)
+    unlock valueAmount of valueAsset
+  }
+  clause cancel(sig: Signature) {
+    verify above(expiry)
+    verify checkTxSig(sender, sig)
+    unlock valueAmount of valueAsset
+  }
+}
+`
+
+var htlcTemplate = newTemplate(HTLCName, htlcSource)
+
+// HTLC is the arguments of hash time-locked contract
+type HTLC struct {
+	Sender    ed25519.PublicKey
+	Recipient ed25519.PublicKey
+	Expiry    int64
+	Hash      []byte
+}
+
+// Program returns the control program of the contract
+func (c *HTLC) Program() ([]byte, error) {
+	if err := checkPubkey("sender", c.Sender); err != nil {
+		return nil, err
+	}
+	if err := checkPubkey("recipient", c.Recipient); err != nil {
+		return nil, err
+	}
+	if err := checkHeight("expiry", c.Expiry); err != nil {
+		return nil, err
+	}
+	if len(c.Hash) != 32 {
+		return nil, errors.WithDetail(ErrBadValue, "hash must be 32 bytes")
+	}
+	return htlcTemplate.instantiate(c.Sender, c.Recipient, vm.Int64Bytes(c.Expiry), c.Hash)
+}
+
+// ParseHTLC parses the program generated by HTLC
+func ParseHTLC(program []byte) (*HTLC, error) {
+	args, err := htlcTemplate.parse(program)
+	if err != nil {
+		return nil, err
+	}
+	return &HTLC{
+		Sender:    ed25519.PublicKey(args[0]),
+		Recipient: ed25519.PublicKey(args[1]),
+		Expiry:    asInt64(args[2]),
+		Hash:      args[3],
+	}, nil
+}
+
+// HTLCCompleteArguments returns the witness arguments of the recipient
+// unlocking the contract by the preimage
+func HTLCCompleteArguments(preimage, sig []byte) ([][]byte, error) {
+	if err := checkSignatures(sig); err != nil {
+		return nil, err
+	}
+	return htlcTemplate.clauseArguments("complete", preimage, sig)
+}
+
+// HTLCCancelArguments returns the witness arguments of the sender taking
+// back the value after expiry
+func HTLCCancelArguments(sig []byte) ([][]byte, error) {
+	if err := checkSignatures(sig); err != nil {
+		return nil, err
+	}
+	return htlcTemplate.clauseArguments("cancel", sig)
+}
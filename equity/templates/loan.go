@@ -0,0 +1,89 @@
+package templates
+
+import (
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+)
+
+// LoanCollateralName is the template name of loan collateral contract
+const LoanCollateralName = "LoanCollateral"
+
+// loanCollateralSource is the loan collateral contract, the borrower gets
+// back the collateral by repaying the loan to the lender by the output 0
+// of the transaction, or the lender takes the collateral after the block
+// height of dueHeight.
+const loanCollateralSource = `
+contract LoanCollateral(assetLoaned: Asset,
+                        amountLoaned: Amount,
+                        dueHeight: Integer,
+                        lender: Program,
+                        borrower: Program) locks valueAmount of valueAsset {
+  clause repay() {
+    lock amountLoaned of assetLoaned with lender
+    lock valueAmount of valueAsset with borrower
+  }
+  clause default() {
+    verify above(dueHeight)
+    lock valueAmount of valueAsset with lender
+  }
+}
+`
+
+var loanCollateralTemplate = newTemplate(LoanCollateralName, loanCollateralSource)
+
+// LoanCollateral is the arguments of loan collateral contract
+type LoanCollateral struct {
+	AssetLoaned  bc.AssetID
+	AmountLoaned int64
+	DueHeight    int64
+	Lender       []byte
+	Borrower     []byte
+}
+
+// Program returns the control program of the contract
+func (c *LoanCollateral) Program() ([]byte, error) {
+	if c.AmountLoaned <= 0 {
+		return nil, errors.WithDetail(ErrBadValue, "amount loaned must be positive")
+	}
+	if err := checkHeight("due height", c.DueHeight); err != nil {
+		return nil, err
+	}
+	if err := checkProgram("lender", c.Lender); err != nil {
+		return nil, err
+	}
+	if err := checkProgram("borrower", c.Borrower); err != nil {
+		return nil, err
+	}
+	return loanCollateralTemplate.instantiate(c.AssetLoaned.Bytes(), vm.Int64Bytes(c.AmountLoaned), vm.Int64Bytes(c.DueHeight), c.Lender, c.Borrower)
+}
+
+// ParseLoanCollateral parses the program generated by LoanCollateral
+func ParseLoanCollateral(program []byte) (*LoanCollateral, error) {
+	args, err := loanCollateralTemplate.parse(program)
+	if err != nil {
+		return nil, err
+	}
+
+	var assetLoaned [32]byte
+	copy(assetLoaned[:], args[0])
+	return &LoanCollateral{
+		AssetLoaned:  bc.NewAssetID(assetLoaned),
+		AmountLoaned: asInt64(args[1]),
+		DueHeight:    asInt64(args[2]),
+		Lender:       args[3],
+		Borrower:     args[4],
+	}, nil
+}
+
+// LoanRepayArguments returns the witness arguments of the borrower
+// repaying the loan
+func LoanRepayArguments() ([][]byte, error) {
+	return loanCollateralTemplate.clauseArguments("repay")
+}
+
+// LoanDefaultArguments returns the witness arguments of the lender taking
+// the collateral after the due height
+func LoanDefaultArguments() ([][]byte, error) {
+	return loanCollateralTemplate.clauseArguments("default")
+}
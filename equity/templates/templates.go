@@ -0,0 +1,155 @@
+// Package templates provides the standard contract templates written in
+// Equity. The templates only use the instructions shared by the bytom
+// and vapor VMs, so their programs and unlock arguments are valid on
+// both chains.
+//
+// Each template has a struct of its contract arguments, which builds the
+// instantiated program by Program, a parser recognizing the program, and
+// the helpers building the witness arguments of its clauses.
+package templates
+
+import (
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/equity/compiler"
+)
+
+var (
+	// ErrBadValue is returned when the contract argument is invalid
+	ErrBadValue = errors.New("bad template value")
+	// ErrNotTemplate is returned when the program is not instantiated from the template
+	ErrNotTemplate = errors.New("program not instantiated from template")
+)
+
+// template is a contract compiled from the Equity source
+type template struct {
+	name     string
+	contract *compiler.Contract
+}
+
+// templates is the list of all the templates, in the order of recognition
+var templates []*template
+
+func newTemplate(name, src string) *template {
+	contracts, err := compiler.Compile(src)
+	if err != nil {
+		panic(errors.Wrap(err, "compiling template "+name))
+	}
+
+	t := &template{name: name, contract: contracts[len(contracts)-1]}
+	templates = append(templates, t)
+	return t
+}
+
+// Contract returns the compiled contract of the template name, nil is
+// returned if there is no such template
+func Contract(name string) *compiler.Contract {
+	for _, t := range templates {
+		if t.name == name {
+			return t.contract
+		}
+	}
+	return nil
+}
+
+// Names returns the names of all the templates
+func Names() []string {
+	names := make([]string, 0, len(templates))
+	for _, t := range templates {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// Recognize returns the name and contract arguments of the template
+// which the program is instantiated from
+func Recognize(program []byte) (string, [][]byte, error) {
+	for _, t := range templates {
+		if args, err := t.parse(program); err == nil {
+			return t.name, args, nil
+		}
+	}
+	return "", nil, ErrNotTemplate
+}
+
+func (t *template) instantiate(args ...[]byte) ([]byte, error) {
+	return t.contract.Instantiate(args)
+}
+
+// parse returns the contract arguments of the program in the order of
+// contract parameters
+//
+//	<argN> ... <arg1> DEPTH <body> FALSE CHECKPREDICATE
+func (t *template) parse(program []byte) ([][]byte, error) {
+	insts, err := vm.ParseProgram(program)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(t.contract.Params)
+	if len(insts) != n+4 {
+		return nil, ErrNotTemplate
+	}
+
+	tail := insts[n:]
+	if tail[0].Op != vm.OP_DEPTH || !tail[1].IsPushdata() || string(tail[1].Data) != string(t.contract.Body) || tail[2].Op != vm.OP_FALSE || tail[3].Op != vm.OP_CHECKPREDICATE {
+		return nil, ErrNotTemplate
+	}
+
+	args := make([][]byte, n)
+	for i, inst := range insts[:n] {
+		if !inst.IsPushdata() {
+			return nil, ErrNotTemplate
+		}
+		args[n-1-i] = inst.Data
+	}
+
+	// the arguments must be what Instantiate accepts
+	if rebuilt, err := t.instantiate(args...); err != nil || string(rebuilt) != string(program) {
+		return nil, ErrNotTemplate
+	}
+	return args, nil
+}
+
+func (t *template) clauseArguments(clause string, args ...[]byte) ([][]byte, error) {
+	return t.contract.ClauseArguments(clause, args)
+}
+
+func checkPubkey(name string, pubkey ed25519.PublicKey) error {
+	if len(pubkey) != ed25519.PublicKeySize {
+		return errors.WithDetailf(ErrBadValue, "%s has %d bytes, expected %d", name, len(pubkey), ed25519.PublicKeySize)
+	}
+	return nil
+}
+
+func checkSignatures(sigs ...[]byte) error {
+	for _, sig := range sigs {
+		if len(sig) != ed25519.SignatureSize {
+			return errors.WithDetailf(ErrBadValue, "signature has %d bytes, expected %d", len(sig), ed25519.SignatureSize)
+		}
+	}
+	return nil
+}
+
+func checkProgram(name string, program []byte) error {
+	if len(program) == 0 {
+		return errors.WithDetailf(ErrBadValue, "%s empty", name)
+	}
+	if _, err := vm.ParseProgram(program); err != nil {
+		return errors.WithDetailf(ErrBadValue, "%s is not a valid program", name)
+	}
+	return nil
+}
+
+func checkHeight(name string, height int64) error {
+	if height < 0 {
+		return errors.WithDetailf(ErrBadValue, "negative %s", name)
+	}
+	return nil
+}
+
+func asInt64(data []byte) int64 {
+	n, _ := vm.AsInt64(data)
+	return n
+}
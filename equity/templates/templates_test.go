@@ -0,0 +1,250 @@
+package templates
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
+	"github.com/bytom-community/wasm/bytom/protocol/validation"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	vaporbc "github.com/bytom-community/wasm/vapor/protocol/bc"
+	vaportypes "github.com/bytom-community/wasm/vapor/protocol/bc/types"
+	vaporvalidation "github.com/bytom-community/wasm/vapor/protocol/validation"
+	vaporvm "github.com/bytom-community/wasm/vapor/protocol/vm"
+)
+
+const testAmount = 10000
+
+var (
+	testAsset  = [32]byte{0x01}
+	loanAsset  = [32]byte{0x02}
+	programA   = []byte{byte(vm.OP_TRUE)}
+	programB   = []byte{byte(vm.OP_1), byte(vm.OP_VERIFY), byte(vm.OP_TRUE)}
+	testHeight = int64(100)
+)
+
+type testOutput struct {
+	asset   [32]byte
+	amount  uint64
+	program []byte
+}
+
+// signFunc signs the signature hash of the contract input by the key
+type signFunc func(xprv chainkd.XPrv) []byte
+
+// execute runs the program as the input of testAmount of testAsset on both
+// bytom and vapor VM, args builds the witness arguments of the clause
+func execute(t *testing.T, program []byte, height uint64, outputs []testOutput, args func(sign signFunc) ([][]byte, error)) (error, error) {
+	txData := &types.TxData{
+		Version: 1,
+		Inputs:  []*types.TxInput{types.NewSpendInput(nil, bc.Hash{V0: 1}, bc.NewAssetID(testAsset), testAmount, 0, program)},
+	}
+	vaporTxData := &vaportypes.TxData{
+		Version: 1,
+		Inputs:  []*vaportypes.TxInput{vaportypes.NewSpendInput(nil, vaporbc.Hash{V0: 1}, vaporbc.NewAssetID(testAsset), testAmount, 0, program)},
+	}
+	for _, out := range outputs {
+		txData.Outputs = append(txData.Outputs, types.NewTxOutput(bc.NewAssetID(out.asset), out.amount, out.program))
+		vaporTxData.Outputs = append(vaporTxData.Outputs, vaportypes.NewIntraChainOutput(vaporbc.NewAssetID(out.asset), out.amount, out.program))
+	}
+
+	sigHash := types.MapTx(txData).SigHash(0)
+	bytomArgs, err := args(func(xprv chainkd.XPrv) []byte { return xprv.Sign(sigHash.Bytes()) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	context, err := validation.InputContext(txData, 0, bytomArgs, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, bytomErr := vm.Verify(context, 100000)
+
+	vaporSigHash := vaportypes.MapTx(vaporTxData).SigHash(0)
+	vaporArgs, err := args(func(xprv chainkd.XPrv) []byte { return xprv.Sign(vaporSigHash.Bytes()) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	vaporContext, err := vaporvalidation.InputContext(vaporTxData, 0, vaporArgs, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, vaporErr := vaporvm.Verify(vaporContext, 100000)
+	return bytomErr, vaporErr
+}
+
+func newTestKeys(t *testing.T, n int) ([]chainkd.XPrv, []ed25519.PublicKey) {
+	var (
+		xprvs   []chainkd.XPrv
+		pubkeys []ed25519.PublicKey
+	)
+	for i := 0; i < n; i++ {
+		xprv, xpub, err := chainkd.NewXKeys(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xprvs, pubkeys = append(xprvs, xprv), append(pubkeys, xpub.PublicKey())
+	}
+	return xprvs, pubkeys
+}
+
+func mustProgram(t *testing.T, c interface{ Program() ([]byte, error) }) []byte {
+	program, err := c.Program()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program
+}
+
+func TestTemplateClauses(t *testing.T) {
+	xprvs, pubkeys := newTestKeys(t, 4)
+	preimage := []byte("preimage")
+	hash := sha256.Sum256(preimage)
+
+	escrow := mustProgram(t, &Escrow{Buyer: pubkeys[0], Seller: pubkeys[1], Arbiter: pubkeys[2], BuyerProgram: programA, SellerProgram: programB})
+	htlc := mustProgram(t, &HTLC{Sender: pubkeys[0], Recipient: pubkeys[1], Expiry: testHeight, Hash: hash[:]})
+	loan := mustProgram(t, &LoanCollateral{AssetLoaned: bc.NewAssetID(loanAsset), AmountLoaned: 5000, DueHeight: testHeight, Lender: programA, Borrower: programB})
+	timeLock := mustProgram(t, &TimeLock{Owner: pubkeys[0], UnlockHeight: testHeight})
+	vesting := mustProgram(t, &Vesting{Payer: pubkeys[0], Recipient: pubkeys[1], ReleaseHeight: testHeight})
+	vault := mustProgram(t, &MultiSigVault{Keys: [3]ed25519.PublicKey{pubkeys[0], pubkeys[1], pubkeys[2]}, RecoveryKey: pubkeys[3], RecoveryHeight: testHeight})
+
+	valueTo := func(program []byte) []testOutput {
+		return []testOutput{{asset: testAsset, amount: testAmount, program: program}}
+	}
+	sigs := func(f func(sigs ...[]byte) ([][]byte, error), keys ...int) func(signFunc) ([][]byte, error) {
+		return func(sign signFunc) ([][]byte, error) {
+			var sigs [][]byte
+			for _, k := range keys {
+				sigs = append(sigs, sign(xprvs[k]))
+			}
+			return f(sigs...)
+		}
+	}
+	escrowRelease := func(sigs ...[]byte) ([][]byte, error) { return EscrowReleaseArguments(sigs[0], sigs[1]) }
+	escrowRefund := func(sigs ...[]byte) ([][]byte, error) { return EscrowRefundArguments(sigs[0], sigs[1]) }
+	htlcComplete := func(preimage []byte) func(sigs ...[]byte) ([][]byte, error) {
+		return func(sigs ...[]byte) ([][]byte, error) { return HTLCCompleteArguments(preimage, sigs[0]) }
+	}
+	htlcCancel := func(sigs ...[]byte) ([][]byte, error) { return HTLCCancelArguments(sigs[0]) }
+	timeLockSpend := func(sigs ...[]byte) ([][]byte, error) { return TimeLockSpendArguments(sigs[0]) }
+	vestingClaim := func(sigs ...[]byte) ([][]byte, error) { return VestingClaimArguments(sigs[0]) }
+	vestingRevoke := func(sigs ...[]byte) ([][]byte, error) { return VestingRevokeArguments(sigs[0]) }
+	vaultSpend := func(sigs ...[]byte) ([][]byte, error) { return MultiSigVaultSpendArguments(sigs[0], sigs[1]) }
+	vaultRecover := func(sigs ...[]byte) ([][]byte, error) { return MultiSigVaultRecoverArguments(sigs[0]) }
+	loanRepay := func(signFunc) ([][]byte, error) { return LoanRepayArguments() }
+	loanDefault := func(signFunc) ([][]byte, error) { return LoanDefaultArguments() }
+	repayOutputs := []testOutput{{asset: loanAsset, amount: 5000, program: programA}, {asset: testAsset, amount: testAmount, program: programB}}
+
+	cases := []struct {
+		desc    string
+		program []byte
+		height  uint64
+		outputs []testOutput
+		args    func(signFunc) ([][]byte, error)
+		valid   bool
+	}{
+		{desc: "escrow release by buyer and seller", program: escrow, outputs: valueTo(programB), args: sigs(escrowRelease, 0, 1), valid: true},
+		{desc: "escrow release by seller and arbiter", program: escrow, outputs: valueTo(programB), args: sigs(escrowRelease, 1, 2), valid: true},
+		{desc: "escrow release to buyer", program: escrow, outputs: valueTo(programA), args: sigs(escrowRelease, 0, 1)},
+		{desc: "escrow release by signatures out of order", program: escrow, outputs: valueTo(programB), args: sigs(escrowRelease, 1, 0)},
+		{desc: "escrow release by other key", program: escrow, outputs: valueTo(programB), args: sigs(escrowRelease, 0, 3)},
+		{desc: "escrow refund by buyer and arbiter", program: escrow, outputs: valueTo(programA), args: sigs(escrowRefund, 0, 2), valid: true},
+		{desc: "escrow refund to seller", program: escrow, outputs: valueTo(programB), args: sigs(escrowRefund, 0, 2)},
+		{desc: "escrow refund of less amount", program: escrow, outputs: []testOutput{{asset: testAsset, amount: testAmount - 1, program: programA}}, args: sigs(escrowRefund, 0, 2)},
+
+		{desc: "htlc complete", program: htlc, args: sigs(htlcComplete(preimage), 1), valid: true},
+		{desc: "htlc complete by wrong preimage", program: htlc, args: sigs(htlcComplete([]byte("other")), 1)},
+		{desc: "htlc complete by sender", program: htlc, args: sigs(htlcComplete(preimage), 0)},
+		{desc: "htlc cancel after expiry", program: htlc, height: uint64(testHeight) + 1, args: sigs(htlcCancel, 0), valid: true},
+		{desc: "htlc cancel at expiry", program: htlc, height: uint64(testHeight), args: sigs(htlcCancel, 0)},
+		{desc: "htlc cancel by recipient", program: htlc, height: uint64(testHeight) + 1, args: sigs(htlcCancel, 1)},
+
+		{desc: "loan repay", program: loan, outputs: repayOutputs, args: loanRepay, valid: true},
+		{desc: "loan repay less amount", program: loan, outputs: []testOutput{{asset: loanAsset, amount: 4999, program: programA}, repayOutputs[1]}, args: loanRepay},
+		{desc: "loan repay to borrower", program: loan, outputs: []testOutput{{asset: loanAsset, amount: 5000, program: programB}, repayOutputs[1]}, args: loanRepay},
+		{desc: "loan repay without collateral output", program: loan, outputs: repayOutputs[:1], args: loanRepay},
+		{desc: "loan default after due height", program: loan, height: uint64(testHeight) + 1, outputs: valueTo(programA), args: loanDefault, valid: true},
+		{desc: "loan default at due height", program: loan, height: uint64(testHeight), outputs: valueTo(programA), args: loanDefault},
+		{desc: "loan default to borrower", program: loan, height: uint64(testHeight) + 1, outputs: valueTo(programB), args: loanDefault},
+
+		{desc: "time lock spend after unlock height", program: timeLock, height: uint64(testHeight) + 1, args: sigs(timeLockSpend, 0), valid: true},
+		{desc: "time lock spend at unlock height", program: timeLock, height: uint64(testHeight), args: sigs(timeLockSpend, 0)},
+		{desc: "time lock spend by other key", program: timeLock, height: uint64(testHeight) + 1, args: sigs(timeLockSpend, 1)},
+
+		{desc: "vesting claim at release height", program: vesting, height: uint64(testHeight), args: sigs(vestingClaim, 1), valid: true},
+		{desc: "vesting claim after release height", program: vesting, height: uint64(testHeight) + 1, args: sigs(vestingClaim, 1), valid: true},
+		{desc: "vesting claim before release height", program: vesting, height: uint64(testHeight) - 1, args: sigs(vestingClaim, 1)},
+		{desc: "vesting claim by payer", program: vesting, height: uint64(testHeight), args: sigs(vestingClaim, 0)},
+		{desc: "vesting revoke before release height", program: vesting, height: uint64(testHeight) - 1, args: sigs(vestingRevoke, 0), valid: true},
+		{desc: "vesting revoke at release height", program: vesting, height: uint64(testHeight), args: sigs(vestingRevoke, 0)},
+		{desc: "vesting revoke by recipient", program: vesting, height: uint64(testHeight) - 1, args: sigs(vestingRevoke, 1)},
+
+		{desc: "vault spend by key 1 and 3", program: vault, args: sigs(vaultSpend, 0, 2), valid: true},
+		{desc: "vault spend by key 2 and 3", program: vault, args: sigs(vaultSpend, 1, 2), valid: true},
+		{desc: "vault spend by recovery key", program: vault, args: sigs(vaultSpend, 0, 3)},
+		{desc: "vault spend by signatures out of order", program: vault, args: sigs(vaultSpend, 2, 0)},
+		{desc: "vault recover after recovery height", program: vault, height: uint64(testHeight) + 1, args: sigs(vaultRecover, 3), valid: true},
+		{desc: "vault recover at recovery height", program: vault, height: uint64(testHeight), args: sigs(vaultRecover, 3)},
+		{desc: "vault recover by key 1", program: vault, height: uint64(testHeight) + 1, args: sigs(vaultRecover, 0)},
+	}
+
+	for _, c := range cases {
+		bytomErr, vaporErr := execute(t, c.program, c.height, c.outputs, c.args)
+		if (bytomErr == nil) != c.valid {
+			t.Errorf("%s: got bytom error %v, want valid %v", c.desc, bytomErr, c.valid)
+		}
+		if (vaporErr == nil) != c.valid {
+			t.Errorf("%s: got vapor error %v, want valid %v", c.desc, vaporErr, c.valid)
+		}
+	}
+}
+
+func TestRecognize(t *testing.T) {
+	_, pubkeys := newTestKeys(t, 4)
+	cases := []struct {
+		name     string
+		contract interface{ Program() ([]byte, error) }
+	}{
+		{name: EscrowName, contract: &Escrow{Buyer: pubkeys[0], Seller: pubkeys[1], Arbiter: pubkeys[2], BuyerProgram: programA, SellerProgram: programB}},
+		{name: HTLCName, contract: &HTLC{Sender: pubkeys[0], Recipient: pubkeys[1], Expiry: testHeight, Hash: make([]byte, 32)}},
+		{name: LoanCollateralName, contract: &LoanCollateral{AssetLoaned: bc.NewAssetID(loanAsset), AmountLoaned: 5000, DueHeight: testHeight, Lender: programA, Borrower: programB}},
+		{name: MultiSigVaultName, contract: &MultiSigVault{Keys: [3]ed25519.PublicKey{pubkeys[0], pubkeys[1], pubkeys[2]}, RecoveryKey: pubkeys[3], RecoveryHeight: testHeight}},
+		{name: TimeLockName, contract: &TimeLock{Owner: pubkeys[0], UnlockHeight: testHeight}},
+		{name: VestingName, contract: &Vesting{Payer: pubkeys[0], Recipient: pubkeys[1], ReleaseHeight: testHeight}},
+	}
+
+	for _, c := range cases {
+		name, _, err := Recognize(mustProgram(t, c.contract))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if name != c.name {
+			t.Errorf("got template %s, want %s", name, c.name)
+		}
+	}
+
+	if _, _, err := Recognize(programA); errors.Root(err) != ErrNotTemplate {
+		t.Errorf("got error %v, want %v", err, ErrNotTemplate)
+	}
+}
+
+func TestParseMultiSigVault(t *testing.T) {
+	_, pubkeys := newTestKeys(t, 4)
+	want := &MultiSigVault{Keys: [3]ed25519.PublicKey{pubkeys[0], pubkeys[1], pubkeys[2]}, RecoveryKey: pubkeys[3], RecoveryHeight: testHeight}
+	got, err := ParseMultiSigVault(mustProgram(t, want))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, key := range got.Keys {
+		if string(key) != string(want.Keys[i]) {
+			t.Errorf("got key %d %x, want %x", i, key, want.Keys[i])
+		}
+	}
+	if string(got.RecoveryKey) != string(want.RecoveryKey) || got.RecoveryHeight != want.RecoveryHeight {
+		t.Errorf("got recovery key %x height %d, want %x height %d", got.RecoveryKey, got.RecoveryHeight, want.RecoveryKey, want.RecoveryHeight)
+	}
+}
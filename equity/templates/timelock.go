@@ -0,0 +1,62 @@
+package templates
+
+import (
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+)
+
+// TimeLockName is the template name of time-lock contract
+const TimeLockName = "TimeLock"
+
+// timeLockSource is the time-lock contract, the owner unlocks the value
+// after the block height of unlockHeight, which is checked by BLOCKHEIGHT.
+const timeLockSource = `
+contract TimeLock(owner: PublicKey,
+                  unlockHeight: Integer) locks valueAmount of valueAsset {
+  clause spend(sig: Signature) {
+    verify above(unlockHeight)
+    verify checkTxSig(owner, sig)
+    unlock valueAmount of valueAsset
+  }
+}
+`
+
+var timeLockTemplate = newTemplate(TimeLockName, timeLockSource)
+
+// TimeLock is the arguments of time-lock contract
+type TimeLock struct {
+	Owner        ed25519.PublicKey
+	UnlockHeight int64
+}
+
+// Program returns the control program of the contract
+func (c *TimeLock) Program() ([]byte, error) {
+	if err := checkPubkey("owner", c.Owner); err != nil {
+		return nil, err
+	}
+	if err := checkHeight("unlock height", c.UnlockHeight); err != nil {
+		return nil, err
+	}
+	return timeLockTemplate.instantiate(c.Owner, vm.Int64Bytes(c.UnlockHeight))
+}
+
+// ParseTimeLock parses the program generated by TimeLock
+func ParseTimeLock(program []byte) (*TimeLock, error) {
+	args, err := timeLockTemplate.parse(program)
+	if err != nil {
+		return nil, err
+	}
+	return &TimeLock{
+		Owner:        ed25519.PublicKey(args[0]),
+		UnlockHeight: asInt64(args[1]),
+	}, nil
+}
+
+// TimeLockSpendArguments returns the witness arguments of the owner
+// unlocking the value after the unlock height
+func TimeLockSpendArguments(sig []byte) ([][]byte, error) {
+	if err := checkSignatures(sig); err != nil {
+		return nil, err
+	}
+	return timeLockTemplate.clauseArguments("spend", sig)
+}
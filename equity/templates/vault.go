@@ -0,0 +1,86 @@
+package templates
+
+import (
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+)
+
+// MultiSigVaultName is the template name of multisig vault contract
+const MultiSigVaultName = "MultiSigVault"
+
+// multiSigVaultSource is the 2-of-3 multisig vault, any two of the keys
+// spend the value at any time, and the recovery key spends it alone after
+// the block height of recoveryHeight in case the keys are lost.
+const multiSigVaultSource = `
+contract MultiSigVault(key1: PublicKey,
+                       key2: PublicKey,
+                       key3: PublicKey,
+                       recoveryKey: PublicKey,
+                       recoveryHeight: Integer) locks valueAmount of valueAsset {
+  clause spend(sig1: Signature, sig2: Signature) {
+    verify checkTxMultiSig([key1, key2, key3], [sig1, sig2])
+    unlock valueAmount of valueAsset
+  }
+  clause recover(sig: Signature) {
+    verify above(recoveryHeight)
+    verify checkTxSig(recoveryKey, sig)
+    unlock valueAmount of valueAsset
+  }
+}
+`
+
+var multiSigVaultTemplate = newTemplate(MultiSigVaultName, multiSigVaultSource)
+
+// MultiSigVault is the arguments of multisig vault contract
+type MultiSigVault struct {
+	Keys           [3]ed25519.PublicKey
+	RecoveryKey    ed25519.PublicKey
+	RecoveryHeight int64
+}
+
+// Program returns the control program of the contract
+func (c *MultiSigVault) Program() ([]byte, error) {
+	for _, key := range c.Keys {
+		if err := checkPubkey("key", key); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkPubkey("recovery key", c.RecoveryKey); err != nil {
+		return nil, err
+	}
+	if err := checkHeight("recovery height", c.RecoveryHeight); err != nil {
+		return nil, err
+	}
+	return multiSigVaultTemplate.instantiate(c.Keys[0], c.Keys[1], c.Keys[2], c.RecoveryKey, vm.Int64Bytes(c.RecoveryHeight))
+}
+
+// ParseMultiSigVault parses the program generated by MultiSigVault
+func ParseMultiSigVault(program []byte) (*MultiSigVault, error) {
+	args, err := multiSigVaultTemplate.parse(program)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiSigVault{
+		Keys:           [3]ed25519.PublicKey{args[0], args[1], args[2]},
+		RecoveryKey:    ed25519.PublicKey(args[3]),
+		RecoveryHeight: asInt64(args[4]),
+	}, nil
+}
+
+// MultiSigVaultSpendArguments returns the witness arguments of two keys
+// spending the value, the signatures must be in the order of keys
+func MultiSigVaultSpendArguments(sig1, sig2 []byte) ([][]byte, error) {
+	if err := checkSignatures(sig1, sig2); err != nil {
+		return nil, err
+	}
+	return multiSigVaultTemplate.clauseArguments("spend", sig1, sig2)
+}
+
+// MultiSigVaultRecoverArguments returns the witness arguments of the
+// recovery key spending the value after the recovery height
+func MultiSigVaultRecoverArguments(sig []byte) ([][]byte, error) {
+	if err := checkSignatures(sig); err != nil {
+		return nil, err
+	}
+	return multiSigVaultTemplate.clauseArguments("recover", sig)
+}
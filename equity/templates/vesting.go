@@ -0,0 +1,114 @@
+package templates
+
+import (
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/math/checked"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+)
+
+// VestingName is the template name of vesting installment contract
+const VestingName = "Vesting"
+
+// vestingSource is an installment of the recurring payment, the recipient
+// claims the value from the block height of releaseHeight inclusive, and
+// the payer can revoke the installment strictly before that, so exactly one
+// of the clauses is available at any block height.
+const vestingSource = `
+contract Vesting(payer: PublicKey,
+                 recipient: PublicKey,
+                 releaseHeight: Integer) locks valueAmount of valueAsset {
+  clause claim(sig: Signature) {
+    verify !below(releaseHeight)
+    verify checkTxSig(recipient, sig)
+    unlock valueAmount of valueAsset
+  }
+  clause revoke(sig: Signature) {
+    verify below(releaseHeight)
+    verify checkTxSig(payer, sig)
+    unlock valueAmount of valueAsset
+  }
+}
+`
+
+var vestingTemplate = newTemplate(VestingName, vestingSource)
+
+// Vesting is the arguments of vesting installment contract
+type Vesting struct {
+	Payer         ed25519.PublicKey
+	Recipient     ed25519.PublicKey
+	ReleaseHeight int64
+}
+
+// Program returns the control program of the contract
+func (c *Vesting) Program() ([]byte, error) {
+	if err := checkPubkey("payer", c.Payer); err != nil {
+		return nil, err
+	}
+	if err := checkPubkey("recipient", c.Recipient); err != nil {
+		return nil, err
+	}
+	if err := checkHeight("release height", c.ReleaseHeight); err != nil {
+		return nil, err
+	}
+	return vestingTemplate.instantiate(c.Payer, c.Recipient, vm.Int64Bytes(c.ReleaseHeight))
+}
+
+// ParseVesting parses the program generated by Vesting
+func ParseVesting(program []byte) (*Vesting, error) {
+	args, err := vestingTemplate.parse(program)
+	if err != nil {
+		return nil, err
+	}
+	return &Vesting{
+		Payer:         ed25519.PublicKey(args[0]),
+		Recipient:     ed25519.PublicKey(args[1]),
+		ReleaseHeight: asInt64(args[2]),
+	}, nil
+}
+
+// VestingSchedule returns the installments of the recurring payment, which
+// are released every period blocks from the start height, the first one is
+// claimable at the start height. Each installment is locked by its own
+// output of the payment transaction.
+func VestingSchedule(payer, recipient ed25519.PublicKey, startHeight, period int64, installments int) ([]*Vesting, error) {
+	if err := checkHeight("start height", startHeight); err != nil {
+		return nil, err
+	}
+	if period <= 0 {
+		return nil, errors.WithDetail(ErrBadValue, "period must be positive")
+	}
+	if installments <= 0 {
+		return nil, errors.WithDetail(ErrBadValue, "installments must be positive")
+	}
+
+	schedule := make([]*Vesting, 0, installments)
+	height := startHeight
+	for i := 0; i < installments; i++ {
+		schedule = append(schedule, &Vesting{Payer: payer, Recipient: recipient, ReleaseHeight: height})
+
+		var ok bool
+		if height, ok = checked.AddInt64(height, period); !ok {
+			return nil, errors.WithDetail(ErrBadValue, "release height overflow")
+		}
+	}
+	return schedule, nil
+}
+
+// VestingClaimArguments returns the witness arguments of the recipient
+// claiming the installment at or after the release height
+func VestingClaimArguments(sig []byte) ([][]byte, error) {
+	if err := checkSignatures(sig); err != nil {
+		return nil, err
+	}
+	return vestingTemplate.clauseArguments("claim", sig)
+}
+
+// VestingRevokeArguments returns the witness arguments of the payer
+// revoking the installment before the release height
+func VestingRevokeArguments(sig []byte) ([][]byte, error) {
+	if err := checkSignatures(sig); err != nil {
+		return nil, err
+	}
+	return vestingTemplate.clauseArguments("revoke", sig)
+}
@@ -42,7 +42,16 @@ func CreateAccount(this js.Value, args []js.Value) interface{} {
 	normalizedAlias := strings.ToLower(strings.TrimSpace(alias))
 
 	signer, err := signers.Create("account", XPubs, quorum, nextIndex)
-	id := signers.IDGenerate()
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	idScheme := args[0].Get("idScheme").String()
+	if lib.IsEmpty(idScheme) {
+		idScheme = signers.LegacyIDScheme
+	}
+	id, err := signers.GenerateID(idScheme, signer)
 	if err != nil {
 		args[1].Set("error", err.Error())
 		return nil
@@ -101,7 +110,7 @@ func CreateAccountReceiver(this js.Value, args []js.Value) interface{} {
 }
 
 func createP2PKH(acc *account.Account, change bool, nextIndex uint64) (*account.CtrlProgram, error) {
-	path := signers.Path(acc.Signer, signers.AccountKeySpace, nextIndex)
+	path := account.DerivePath(acc, nextIndex)
 	derivedXPubs := chainkd.DeriveXPubs(acc.XPubs, path)
 	derivedPK := derivedXPubs[0].PublicKey()
 	pubHash := crypto.Ripemd160(derivedPK)
@@ -126,7 +135,7 @@ func createP2PKH(acc *account.Account, change bool, nextIndex uint64) (*account.
 }
 
 func createP2SH(acc *account.Account, change bool, nextIndex uint64) (*account.CtrlProgram, error) {
-	path := signers.Path(acc.Signer, signers.AccountKeySpace, nextIndex)
+	path := account.DerivePath(acc, nextIndex)
 	derivedXPubs := chainkd.DeriveXPubs(acc.XPubs, path)
 	derivedPKs := chainkd.XPubKeys(derivedXPubs)
 	signScript, err := vmutil.P2SPMultiSigProgram(derivedPKs, acc.Quorum)
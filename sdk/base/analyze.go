@@ -0,0 +1,89 @@
+package base
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/sdk/lib"
+	vaporvm "github.com/bytom-community/wasm/vapor/protocol/vm"
+)
+
+// RespWarning is the warning of program analysis
+type RespWarning struct {
+	Offset  uint32 `json:"offset"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// RespAnalyzeProgram is the response of AnalyzeProgram
+type RespAnalyzeProgram struct {
+	Warnings     []RespWarning `json:"warnings"`
+	Arguments    int           `json:"arguments"`
+	Gas          int64         `json:"gas"`
+	GasUnbounded bool          `json:"gas_unbounded"`
+}
+
+// AnalyzeProgram check the program statically for the problems on every path
+func AnalyzeProgram(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	programStr := args[0].Get("program").String()
+	if lib.IsEmpty(programStr) {
+		args[1].Set("error", "program empty")
+		return nil
+	}
+
+	program, err := hex.DecodeString(programStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	dialect, err := checkVMDialect(args[0].Get("vm").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	nargs := -1
+	if n := args[0].Get("arguments_count"); n.Type() == js.TypeNumber {
+		nargs = n.Int()
+	}
+
+	gasLimit := defaultGasLimit
+	if g := args[0].Get("gas_limit"); g.Type() == js.TypeNumber {
+		gasLimit = int64(g.Int())
+	}
+
+	res := RespAnalyzeProgram{Warnings: []RespWarning{}}
+	if dialect == vmVapor {
+		analysis, err := vaporvm.AnalyzeProgram(program, nargs, gasLimit)
+		if err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+		for _, w := range analysis.Warnings {
+			res.Warnings = append(res.Warnings, RespWarning{Offset: w.Offset, Kind: w.Kind, Message: w.Message})
+		}
+		res.Arguments, res.Gas, res.GasUnbounded = analysis.Arguments, analysis.Gas, analysis.GasUnbounded
+	} else {
+		analysis, err := vm.AnalyzeProgram(program, nargs, gasLimit)
+		if err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+		for _, w := range analysis.Warnings {
+			res.Warnings = append(res.Warnings, RespWarning{Offset: w.Offset, Kind: w.Kind, Message: w.Message})
+		}
+		res.Arguments, res.Gas, res.GasUnbounded = analysis.Arguments, analysis.Gas, analysis.GasUnbounded
+	}
+
+	j, err := json.Marshal(res)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
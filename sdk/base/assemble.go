@@ -0,0 +1,169 @@
+package base
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"syscall/js"
+
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/sdk/lib"
+	vaporvm "github.com/bytom-community/wasm/vapor/protocol/vm"
+)
+
+// the dialect of VM
+const (
+	vmBytom = "bytom"
+	vmVapor = "vapor"
+)
+
+// RespAssembleProgram is the response of AssembleProgram
+type RespAssembleProgram struct {
+	Program chainjson.HexBytes `json:"program"`
+}
+
+// RespDisassembleProgram is the response of DisassembleProgram
+type RespDisassembleProgram struct {
+	Assembly string            `json:"assembly"`
+	Ops      []*DisassembledOp `json:"ops"`
+	EndLabel string            `json:"end_label,omitempty"`
+}
+
+// DisassembledOp is an op of the disassembled program
+type DisassembledOp struct {
+	Offset      uint32             `json:"offset"`
+	Label       string             `json:"label,omitempty"`
+	Op          string             `json:"op"`
+	Data        chainjson.HexBytes `json:"data,omitempty"`
+	Target      *uint32            `json:"target,omitempty"`
+	TargetLabel string             `json:"target_label,omitempty"`
+}
+
+func checkVMDialect(dialect string) (string, error) {
+	switch {
+	case lib.IsEmpty(dialect):
+		return vmBytom, nil
+	case dialect == vmBytom || dialect == vmVapor:
+		return dialect, nil
+	default:
+		return "", errors.New("bad vm " + dialect)
+	}
+}
+
+func assembleProgram(dialect, assembly string) ([]byte, error) {
+	if dialect == vmVapor {
+		return vaporvm.Assemble(assembly)
+	}
+	return vm.Assemble(assembly)
+}
+
+func disassembleProgram(dialect string, program []byte) (*RespDisassembleProgram, error) {
+	resp := &RespDisassembleProgram{Ops: []*DisassembledOp{}}
+	var labels map[uint32]string
+	if dialect == vmVapor {
+		dops, l, err := vaporvm.DisassembleOps(program)
+		if err != nil {
+			return nil, err
+		}
+
+		labels = l
+		for _, dop := range dops {
+			resp.Ops = append(resp.Ops, newDisassembledOp(dop.Offset, dop.Label, dop.Op.String(), dop.Data, dop.Op == vaporvm.OP_JUMP || dop.Op == vaporvm.OP_JUMPIF, dop.Target, dop.TargetLabel))
+		}
+		if resp.Assembly, err = vaporvm.Disassemble(program); err != nil {
+			return nil, err
+		}
+	} else {
+		dops, l, err := vm.DisassembleOps(program)
+		if err != nil {
+			return nil, err
+		}
+
+		labels = l
+		for _, dop := range dops {
+			resp.Ops = append(resp.Ops, newDisassembledOp(dop.Offset, dop.Label, dop.Op.String(), dop.Data, dop.Op == vm.OP_JUMP || dop.Op == vm.OP_JUMPIF, dop.Target, dop.TargetLabel))
+		}
+		if resp.Assembly, err = vm.Disassemble(program); err != nil {
+			return nil, err
+		}
+	}
+
+	resp.EndLabel = labels[uint32(len(program))]
+	return resp, nil
+}
+
+func newDisassembledOp(offset uint32, label, op string, data []byte, isJump bool, target uint32, targetLabel string) *DisassembledOp {
+	dop := &DisassembledOp{Offset: offset, Label: label, Op: op, Data: data}
+	if isJump {
+		dop.Target, dop.TargetLabel = &target, targetLabel
+	}
+	return dop
+}
+
+// AssembleProgram assemble the program of bytom or vapor VM, the jump-target labels are resolved
+func AssembleProgram(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	assembly := args[0].Get("assembly").String()
+	if lib.IsEmpty(assembly) {
+		args[1].Set("error", "assembly empty")
+		return nil
+	}
+
+	dialect, err := checkVMDialect(args[0].Get("vm").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	program, err := assembleProgram(dialect, assembly)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(RespAssembleProgram{Program: program})
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// DisassembleProgram disassemble the program of bytom or vapor VM into ops with the offset,
+// mnemonic and pushed data, the jump targets are labeled
+func DisassembleProgram(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	programStr := args[0].Get("program").String()
+	if lib.IsEmpty(programStr) {
+		args[1].Set("error", "program empty")
+		return nil
+	}
+
+	dialect, err := checkVMDialect(args[0].Get("vm").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	program, err := hex.DecodeString(programStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	resp, err := disassembleProgram(dialect, program)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
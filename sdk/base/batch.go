@@ -0,0 +1,161 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/pseudohsm"
+	"github.com/bytom-community/wasm/bytom/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/sdk/lib"
+)
+
+// RespBatchSign is the result of signing a template in batch
+type RespBatchSign struct {
+	Index        int                 `json:"index"`
+	Template     *txbuilder.Template `json:"transaction"`
+	SignComplete bool                `json:"sign_complete"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// RespSignTemplates is the response of SignTemplates
+type RespSignTemplates struct {
+	Results []*RespBatchSign `json:"results"`
+}
+
+func newRespBatchSign(res *txbuilder.BatchResult) *RespBatchSign {
+	resp := &RespBatchSign{Index: res.Index, Template: res.Template, SignComplete: res.SignComplete}
+	if res.Err != nil {
+		resp.Error = res.Err.Error()
+	}
+	return resp
+}
+
+// decryptKeys decrypt the keys required by the xpubs, each key is decrypted only once
+func decryptKeys(accountJSON, keysJSON string, xpubs []chainkd.XPub, password string) (map[chainkd.XPub]*pseudohsm.XKey, error) {
+	var rawKeys []json.RawMessage
+	if err := json.Unmarshal([]byte(keysJSON), &rawKeys); err != nil {
+		return nil, err
+	}
+
+	required := make(map[chainkd.XPub]bool, len(xpubs))
+	for _, xpub := range xpubs {
+		required[xpub] = true
+	}
+
+	keys := make(map[chainkd.XPub]*pseudohsm.XKey)
+	for _, rawKey := range rawKeys {
+		var k struct {
+			XPub chainkd.XPub `json:"xpub"`
+		}
+		if err := json.Unmarshal(rawKey, &k); err != nil {
+			return nil, err
+		}
+		if !required[k.XPub] || keys[k.XPub] != nil {
+			continue
+		}
+
+		if err := checkWatchOnly(accountJSON, string(rawKey)); err != nil {
+			return nil, err
+		}
+		key, err := pseudohsm.DecryptKey(rawKey, password)
+		if err != nil {
+			return nil, err
+		}
+		keys[key.XPub] = key
+	}
+	return keys, nil
+}
+
+// checkTemplatesTxID check the transactions of templates are the ones of the summaries
+// confirmed by the user, the tx_ids are opt-in and matched with the templates by index
+func checkTemplatesTxID(txIDsJSON string, tpls []*txbuilder.Template) error {
+	if lib.IsEmpty(txIDsJSON) {
+		return nil
+	}
+
+	var txIDs []string
+	if err := json.Unmarshal([]byte(txIDsJSON), &txIDs); err != nil {
+		return err
+	}
+	if len(txIDs) != len(tpls) {
+		return errors.WithDetailf(errTxIDMismatch, "%d tx_ids for %d templates", len(txIDs), len(tpls))
+	}
+
+	for i, tpl := range tpls {
+		if err := checkTemplateTxID(txIDs[i], tpl); err != nil {
+			return errors.WithDetailf(err, "template %d", i)
+		}
+	}
+	return nil
+}
+
+// zeroKeys zero the private keys decrypted for the call, so they don't stay in memory
+func zeroKeys(keys map[chainkd.XPub]*pseudohsm.XKey) {
+	for xpub, key := range keys {
+		key.XPrv = chainkd.XPrv{}
+		delete(keys, xpub)
+	}
+}
+
+// SignTemplates sign the transaction templates in batch, the keys are decrypted once and the
+// templates are signed concurrently, the result of each template is reported by the progress
+// callback in the order of completion. The templates are refused before the keys are decrypted
+// if any transaction mismatches the tx_ids given.
+func SignTemplates(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	templates := args[0].Get("templates").String()
+	password := args[0].Get("password").String()
+	keysJSON := args[0].Get("keys").String()
+	if lib.IsEmpty(templates) || lib.IsEmpty(password) || lib.IsEmpty(keysJSON) {
+		args[1].Set("error", "args empty")
+		return nil
+	}
+
+	var tpls []*txbuilder.Template
+	if err := json.Unmarshal([]byte(templates), &tpls); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if err := checkTemplatesTxID(args[0].Get("tx_ids").String(), tpls); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	keys, err := decryptKeys(args[0].Get("account").String(), keysJSON, txbuilder.RequiredXPubs(tpls), password)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	defer zeroKeys(keys)
+
+	var workers int
+	if w := args[0].Get("workers"); w.Type() == js.TypeNumber {
+		workers = w.Int()
+	}
+
+	var progress func(*txbuilder.BatchResult)
+	if callback := args[0].Get("progress"); callback.Type() == js.TypeFunction {
+		progress = func(res *txbuilder.BatchResult) {
+			if j, err := json.Marshal(newRespBatchSign(res)); err == nil {
+				callback.Invoke(string(j))
+			}
+		}
+	}
+
+	results := txbuilder.SignBatch(context.Background(), tpls, password, keysSignFunc(keys), workers, progress)
+	ret := RespSignTemplates{Results: make([]*RespBatchSign, 0, len(results))}
+	for _, res := range results {
+		ret.Results = append(ret.Results, newRespBatchSign(res))
+	}
+
+	j, err := json.Marshal(ret)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
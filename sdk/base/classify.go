@@ -0,0 +1,61 @@
+package base
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/sdk/classify"
+	"github.com/bytom-community/wasm/sdk/lib"
+)
+
+// ClassifyProgram tell the type of control program and parse its fields
+func ClassifyProgram(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	programStr := args[0].Get("program").String()
+	if lib.IsEmpty(programStr) {
+		args[1].Set("error", "program empty")
+		return nil
+	}
+
+	program, err := hex.DecodeString(programStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	dialect, err := checkVMDialect(args[0].Get("vm").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	var res *classify.Program
+	if voteStr := args[0].Get("vote").String(); !lib.IsEmpty(voteStr) {
+		if dialect != vmVapor {
+			args[1].Set("error", "vote is only for vapor")
+			return nil
+		}
+
+		vote, decodeErr := hex.DecodeString(voteStr)
+		if decodeErr != nil {
+			args[1].Set("error", decodeErr.Error())
+			return nil
+		}
+		res, err = classify.ClassifyVote(program, vote)
+	} else {
+		res, err = classify.Classify(dialect, program)
+	}
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(res)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
@@ -0,0 +1,330 @@
+package base
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/bytom/common"
+	"github.com/bytom-community/wasm/bytom/crypto"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+	"github.com/bytom-community/wasm/equity/compiler"
+	"github.com/bytom-community/wasm/equity/templates"
+	"github.com/bytom-community/wasm/sdk/lib"
+	vaporcommon "github.com/bytom-community/wasm/vapor/common"
+	vaporconsensus "github.com/bytom-community/wasm/vapor/consensus"
+)
+
+// RespInstantiateContract is the response of InstantiateContract and WrapP2WSH
+type RespInstantiateContract struct {
+	Program      chainjson.HexBytes `json:"program"`
+	ScriptHash   chainjson.HexBytes `json:"script_hash"`
+	P2WSHProgram chainjson.HexBytes `json:"p2wsh_program"`
+	Address      string             `json:"address"`
+}
+
+// RespClauseArguments is the response of BuildClauseArguments and BuildP2WSHWitness
+type RespClauseArguments struct {
+	Arguments []chainjson.HexBytes    `json:"arguments"`
+	Witness   []txbuilder.DataWitness `json:"witness_components"`
+}
+
+// CompileContract compile the Equity contracts, return the bytecode and ABI of contracts
+func CompileContract(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	source := args[0].Get("source").String()
+	if lib.IsEmpty(source) {
+		args[1].Set("error", "source empty")
+		return nil
+	}
+
+	contracts, err := compiler.Compile(source)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(contracts)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// RespTemplateArgument is the contract argument of the recognized template
+type RespTemplateArgument struct {
+	Name  string             `json:"name"`
+	Type  string             `json:"type"`
+	Value chainjson.HexBytes `json:"value"`
+}
+
+// RespRecognizeTemplate is the response of RecognizeContractTemplate
+type RespRecognizeTemplate struct {
+	Template  string                  `json:"template"`
+	Arguments []*RespTemplateArgument `json:"arguments"`
+}
+
+// getContract return the contract by the template name, the ABI, or by the name from the compiled source
+func getContract(arg js.Value) (*compiler.Contract, error) {
+	if name := arg.Get("template").String(); !lib.IsEmpty(name) {
+		contract := templates.Contract(name)
+		if contract == nil {
+			return nil, errors.New("template " + name + " not found")
+		}
+		return contract, nil
+	}
+
+	if contractJSON := arg.Get("contract").String(); !lib.IsEmpty(contractJSON) {
+		contract := &compiler.Contract{}
+		if err := json.Unmarshal([]byte(contractJSON), contract); err != nil {
+			return nil, err
+		}
+		return contract, nil
+	}
+
+	source := arg.Get("source").String()
+	if lib.IsEmpty(source) {
+		return nil, errors.New("contract empty")
+	}
+	contracts, err := compiler.Compile(source)
+	if err != nil {
+		return nil, err
+	}
+
+	name := arg.Get("name").String()
+	if lib.IsEmpty(name) {
+		return contracts[len(contracts)-1], nil
+	}
+	for _, contract := range contracts {
+		if contract.Name == name {
+			return contract, nil
+		}
+	}
+	return nil, errors.New("contract " + name + " not found")
+}
+
+// convertContractArgs convert the JSON array of ContractArgument into bytes, the
+// array arguments are flattened
+func convertContractArgs(argsJSON string) ([][]byte, error) {
+	var contractArgs []ContractArgument
+	if !lib.IsEmpty(argsJSON) {
+		if err := json.Unmarshal([]byte(argsJSON), &contractArgs); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([][]byte, 0, len(contractArgs))
+	for _, arg := range contractArgs {
+		datas, err := ConvertContractArgs(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, data := range datas {
+			result = append(result, data.Value)
+		}
+	}
+	return result, nil
+}
+
+// InstantiateContract instantiate the contract with the arguments, return the program and its P2WSH address
+func InstantiateContract(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	contract, err := getContract(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	contractArgs, err := convertContractArgs(args[0].Get("arguments").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	dialect, err := checkVMDialect(args[0].Get("vm").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	program, err := contract.Instantiate(contractArgs)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	resp, err := wrapP2WSH(dialect, program, args[0].Get("network").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// wrapP2WSH return the script hash of program, and its P2WSH program and address on the network of chain
+func wrapP2WSH(dialect string, program []byte, network string) (*RespInstantiateContract, error) {
+	scriptHash := crypto.Sha256(program)
+	p2wshProgram, err := vmutil.P2WSHProgram(scriptHash)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := scriptHashAddress(dialect, scriptHash, network)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RespInstantiateContract{
+		Program:      program,
+		ScriptHash:   scriptHash,
+		P2WSHProgram: p2wshProgram,
+		Address:      address,
+	}, nil
+}
+
+// scriptHashAddress return the P2WSH address of the script hash on the network of chain
+func scriptHashAddress(dialect string, scriptHash []byte, network string) (string, error) {
+	if dialect == vmVapor {
+		netParams := &vaporconsensus.ActiveNetParams
+		if !lib.IsEmpty(network) {
+			params, ok := vaporconsensus.NetParams[network]
+			if !ok {
+				return "", errors.New("bad network")
+			}
+			netParams = &params
+		}
+
+		address, err := vaporcommon.NewAddressWitnessScriptHash(scriptHash, netParams)
+		if err != nil {
+			return "", err
+		}
+		return address.EncodeAddress(), nil
+	}
+
+	netParams, err := getNetParams(network)
+	if err != nil {
+		return "", err
+	}
+
+	address, err := common.NewAddressWitnessScriptHash(scriptHash, netParams)
+	if err != nil {
+		return "", err
+	}
+	return address.EncodeAddress(), nil
+}
+
+// BuildClauseArguments build the witness arguments unlocking the clause of contract
+func BuildClauseArguments(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	clause := args[0].Get("clause").String()
+	if lib.IsEmpty(clause) {
+		args[1].Set("error", "clause empty")
+		return nil
+	}
+
+	contract, err := getContract(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	clauseArgs, err := convertContractArgs(args[0].Get("arguments").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	witness, err := contract.ClauseArguments(clause, clauseArgs)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(newRespClauseArguments(witness))
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+func newRespClauseArguments(witness [][]byte) *RespClauseArguments {
+	resp := &RespClauseArguments{
+		Arguments: make([]chainjson.HexBytes, 0, len(witness)),
+		Witness:   make([]txbuilder.DataWitness, 0, len(witness)),
+	}
+	for _, arg := range witness {
+		resp.Arguments = append(resp.Arguments, arg)
+		resp.Witness = append(resp.Witness, txbuilder.DataWitness(arg))
+	}
+	return resp
+}
+
+// RecognizeContractTemplate recognize the standard contract template which the program is instantiated from
+func RecognizeContractTemplate(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	programStr := args[0].Get("program").String()
+	if lib.IsEmpty(programStr) {
+		args[1].Set("error", "program empty")
+		return nil
+	}
+
+	program, err := hex.DecodeString(programStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	name, contractArgs, err := templates.Recognize(program)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	resp := &RespRecognizeTemplate{Template: name}
+	for i, param := range templates.Contract(name).Params {
+		resp.Arguments = append(resp.Arguments, &RespTemplateArgument{
+			Name:  param.Name,
+			Type:  param.ArgType,
+			Value: contractArgs[i],
+		})
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// GetContractTemplates return the contracts of the standard contract templates
+func GetContractTemplates(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	contracts := []*compiler.Contract{}
+	for _, name := range templates.Names() {
+		contracts = append(contracts, templates.Contract(name))
+	}
+
+	j, err := json.Marshal(contracts)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
@@ -0,0 +1,400 @@
+package base
+
+import (
+	"encoding/json"
+	"sync"
+	"syscall/js"
+
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/validation"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/sdk/lib"
+	vaporvalidation "github.com/bytom-community/wasm/vapor/protocol/validation"
+	vaporvm "github.com/bytom-community/wasm/vapor/protocol/vm"
+)
+
+// defaultGasLimit is the max gas amount of a bytom transaction
+const defaultGasLimit int64 = 200000
+
+// programDebugger is satisfied by the debuggers of both bytom and vapor VM
+type programDebugger interface {
+	Step() error
+	Continue() error
+	SetBreakpoint(pc uint32)
+	ClearBreakpoint(pc uint32)
+	PC() uint32
+	Done() bool
+	Result() (int64, error)
+}
+
+// debugSession is a debugger created by CreateDebugger, the trace is returned
+// incrementally by each step
+type debugSession struct {
+	debugger programDebugger
+	trace    func() []*TraceStep
+	sent     int
+}
+
+var (
+	debugSessions  = make(map[int]*debugSession)
+	nextDebugID    = 1
+	debugSessionMu sync.Mutex
+)
+
+// TraceStep is the state of VM after an op is executed
+type TraceStep struct {
+	Depth        int                  `json:"depth"`
+	PC           uint32               `json:"pc"`
+	Op           string               `json:"op"`
+	Data         chainjson.HexBytes   `json:"data,omitempty"`
+	DataStack    []chainjson.HexBytes `json:"data_stack"`
+	AltStack     []chainjson.HexBytes `json:"alt_stack"`
+	RunLimit     int64                `json:"run_limit"`
+	DeferredCost int64                `json:"deferred_cost"`
+	Error        string               `json:"error,omitempty"`
+}
+
+// RespDebugger is the response of the debugger functions
+type RespDebugger struct {
+	ID      int          `json:"id"`
+	PC      uint32       `json:"pc"`
+	Done    bool         `json:"done"`
+	Trace   []*TraceStep `json:"trace"`
+	GasLeft int64        `json:"gas_left"`
+	Valid   bool         `json:"valid"`
+	Error   string       `json:"error,omitempty"`
+}
+
+func newTraceStep(depth int, pc uint32, op string, data []byte, dataStack, altStack [][]byte, runLimit, deferredCost int64, err error) *TraceStep {
+	step := &TraceStep{
+		Depth:        depth,
+		PC:           pc,
+		Op:           op,
+		Data:         data,
+		DataStack:    make([]chainjson.HexBytes, 0, len(dataStack)),
+		AltStack:     make([]chainjson.HexBytes, 0, len(altStack)),
+		RunLimit:     runLimit,
+		DeferredCost: deferredCost,
+	}
+	for _, item := range dataStack {
+		step.DataStack = append(step.DataStack, item)
+	}
+	for _, item := range altStack {
+		step.AltStack = append(step.AltStack, item)
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	return step
+}
+
+// debugTx is the transaction context of the debugged program, the program
+// is evaluated as the input at position of the transaction
+type debugTx struct {
+	rawTx       string
+	proposal    *ProposedTx
+	position    uint32
+	blockHeight uint64
+}
+
+// bytomDebugContext returns the context of the debugged program, the program
+// of input is debugged if program is empty, and the arguments of input are
+// used if arguments is nil
+func bytomDebugContext(program []byte, arguments [][]byte, tx *debugTx) (*vm.Context, error) {
+	if tx == nil {
+		return &vm.Context{VMVersion: 1, Code: program, Arguments: arguments}, nil
+	}
+
+	txData, err := bytomTxData(tx.rawTx, tx.proposal)
+	if err != nil {
+		return nil, err
+	}
+	if int(tx.position) >= len(txData.Inputs) {
+		return nil, errors.WithDetailf(validation.ErrBadInputIdx, "position %d, %d inputs", tx.position, len(txData.Inputs))
+	}
+	if arguments == nil {
+		arguments = txData.Inputs[tx.position].Arguments()
+	}
+
+	context, err := validation.InputContext(txData, tx.position, arguments, tx.blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	if len(program) > 0 {
+		context.Code = program
+	}
+	return context, nil
+}
+
+// vaporDebugContext is the same as bytomDebugContext for vapor VM
+func vaporDebugContext(program []byte, arguments [][]byte, tx *debugTx) (*vaporvm.Context, error) {
+	if tx == nil {
+		return &vaporvm.Context{VMVersion: 1, Code: program, Arguments: arguments}, nil
+	}
+
+	txData, err := vaporTxData(tx.rawTx, tx.proposal)
+	if err != nil {
+		return nil, err
+	}
+	if int(tx.position) >= len(txData.Inputs) {
+		return nil, errors.WithDetailf(vaporvalidation.ErrBadInputIdx, "position %d, %d inputs", tx.position, len(txData.Inputs))
+	}
+	if arguments == nil {
+		arguments = txData.Inputs[tx.position].Arguments()
+	}
+
+	context, err := vaporvalidation.InputContext(txData, tx.position, arguments, tx.blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	if len(program) > 0 {
+		context.Code = program
+	}
+	return context, nil
+}
+
+func newDebugSession(dialect string, program []byte, arguments [][]byte, tx *debugTx, gasLimit int64) (*debugSession, error) {
+	if dialect == vmVapor {
+		context, err := vaporDebugContext(program, arguments, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := vaporvm.NewDebugger(context, gasLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		return &debugSession{debugger: d, trace: func() []*TraceStep {
+			var steps []*TraceStep
+			for _, s := range d.Trace() {
+				steps = append(steps, newTraceStep(s.Depth, s.PC, s.Op.String(), s.Data, s.DataStack, s.AltStack, s.RunLimit, s.DeferredCost, s.Err))
+			}
+			return steps
+		}}, nil
+	}
+
+	context, err := bytomDebugContext(program, arguments, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := vm.NewDebugger(context, gasLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &debugSession{debugger: d, trace: func() []*TraceStep {
+		var steps []*TraceStep
+		for _, s := range d.Trace() {
+			steps = append(steps, newTraceStep(s.Depth, s.PC, s.Op.String(), s.Data, s.DataStack, s.AltStack, s.RunLimit, s.DeferredCost, s.Err))
+		}
+		return steps
+	}}, nil
+}
+
+// response return the state of debugger with the trace since the last
+// response, stepErr is the error returned by the executed ops
+func (s *debugSession) response(id int, stepErr error) *RespDebugger {
+	trace := s.trace()
+	resp := &RespDebugger{ID: id, PC: s.debugger.PC(), Done: s.debugger.Done(), Trace: trace[s.sent:]}
+	s.sent = len(trace)
+	if stepErr != nil {
+		resp.Error = stepErr.Error()
+	}
+	if resp.Done {
+		gasLeft, err := s.debugger.Result()
+		resp.GasLeft, resp.Valid = gasLeft, err == nil
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	}
+	return resp
+}
+
+func getDebugSession(arg js.Value) (int, *debugSession, error) {
+	if arg.Get("id").Type() != js.TypeNumber {
+		return 0, nil, errors.New("id empty")
+	}
+
+	id := arg.Get("id").Int()
+	debugSessionMu.Lock()
+	defer debugSessionMu.Unlock()
+	s, ok := debugSessions[id]
+	if !ok {
+		return 0, nil, errors.New("debugger not found")
+	}
+	return id, s, nil
+}
+
+// setBreakpoints set the breakpoints given by the json array of pc
+func setBreakpoints(debugger programDebugger, breakpointsJSON string) error {
+	if lib.IsEmpty(breakpointsJSON) {
+		return nil
+	}
+
+	var breakpoints []uint32
+	if err := json.Unmarshal([]byte(breakpointsJSON), &breakpoints); err != nil {
+		return err
+	}
+	for _, pc := range breakpoints {
+		debugger.SetBreakpoint(pc)
+	}
+	return nil
+}
+
+func setDebuggerResponse(ret js.Value, resp *RespDebugger) {
+	j, err := json.Marshal(resp)
+	if err != nil {
+		ret.Set("error", err.Error())
+		return
+	}
+	ret.Set("data", string(j))
+}
+
+// CreateDebugger create the step-by-step debugger of the program with the arguments,
+// the program is evaluated as the input of the transaction if the transaction is given
+func CreateDebugger(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	var tx *debugTx
+	if !lib.IsEmpty(args[0].Get("raw_transaction").String()) || !lib.IsEmpty(args[0].Get("transaction").String()) {
+		rawTx, proposal, err := parseTxArgs(args[0])
+		if err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+
+		tx = &debugTx{rawTx: rawTx, proposal: proposal}
+		if p := args[0].Get("position"); p.Type() == js.TypeNumber {
+			tx.position = uint32(p.Int())
+		}
+		if h := args[0].Get("block_height"); h.Type() == js.TypeNumber {
+			tx.blockHeight = uint64(h.Int())
+		}
+	}
+
+	programStr := args[0].Get("program").String()
+	if lib.IsEmpty(programStr) && tx == nil {
+		args[1].Set("error", "program empty")
+		return nil
+	}
+
+	var program chainjson.HexBytes
+	if !lib.IsEmpty(programStr) {
+		if err := program.UnmarshalText([]byte(programStr)); err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+	}
+
+	dialect, err := checkVMDialect(args[0].Get("vm").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	var arguments [][]byte
+	if argumentsStr := args[0].Get("arguments").String(); !lib.IsEmpty(argumentsStr) {
+		var hexArgs []chainjson.HexBytes
+		if err := json.Unmarshal([]byte(argumentsStr), &hexArgs); err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+		arguments = make([][]byte, 0, len(hexArgs))
+		for _, arg := range hexArgs {
+			arguments = append(arguments, arg)
+		}
+	}
+
+	gasLimit := defaultGasLimit
+	if g := args[0].Get("gas_limit"); g.Type() == js.TypeNumber {
+		gasLimit = int64(g.Int())
+	}
+
+	s, err := newDebugSession(dialect, program, arguments, tx, gasLimit)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if err := setBreakpoints(s.debugger, args[0].Get("breakpoints").String()); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	debugSessionMu.Lock()
+	id := nextDebugID
+	nextDebugID++
+	debugSessions[id] = s
+	debugSessionMu.Unlock()
+
+	setDebuggerResponse(args[1], s.response(id, nil))
+	return nil
+}
+
+// StepDebugger execute the next ops of the debugger, one op is executed by default
+func StepDebugger(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	id, s, err := getDebugSession(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	steps := 1
+	if n := args[0].Get("steps"); n.Type() == js.TypeNumber {
+		steps = n.Int()
+	}
+	var stepErr error
+	for i := 0; i < steps && !s.debugger.Done() && stepErr == nil; i++ {
+		stepErr = s.debugger.Step()
+	}
+
+	setDebuggerResponse(args[1], s.response(id, stepErr))
+	return nil
+}
+
+// ContinueDebugger execute the ops of the debugger until the program is done or a breakpoint is met
+func ContinueDebugger(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	id, s, err := getDebugSession(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	if err := setBreakpoints(s.debugger, args[0].Get("breakpoints").String()); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if breakpointsStr := args[0].Get("clear_breakpoints").String(); !lib.IsEmpty(breakpointsStr) {
+		var breakpoints []uint32
+		if err := json.Unmarshal([]byte(breakpointsStr), &breakpoints); err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+		for _, pc := range breakpoints {
+			s.debugger.ClearBreakpoint(pc)
+		}
+	}
+
+	err = s.debugger.Continue()
+	setDebuggerResponse(args[1], s.response(id, err))
+	return nil
+}
+
+// CloseDebugger release the debugger
+func CloseDebugger(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	id, _, err := getDebugSession(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	debugSessionMu.Lock()
+	delete(debugSessions, id)
+	debugSessionMu.Unlock()
+	args[1].Set("data", "")
+	return nil
+}
@@ -0,0 +1,100 @@
+package base
+
+import (
+	"encoding/json"
+	"strings"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/account"
+	"github.com/bytom-community/wasm/sdk/lib"
+)
+
+// RespAccountDescriptor is the response of ExportAccountDescriptor
+type RespAccountDescriptor struct {
+	Descriptor string `json:"descriptor"`
+}
+
+// ExportAccountDescriptor export account to the versioned and checksummed descriptor
+func ExportAccountDescriptor(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1]) //end func call
+	var acc account.Account
+	if err := json.Unmarshal([]byte(args[0].Get("account").String()), &acc); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if acc.Signer == nil {
+		args[1].Set("error", "account signer empty")
+		return nil
+	}
+
+	chain := args[0].Get("chain").String()
+	if lib.IsEmpty(chain) {
+		chain = account.ChainBytom
+	}
+	network := args[0].Get("network").String()
+	if lib.IsEmpty(network) {
+		network = "mainnet"
+	}
+	if _, err := getNetParams(network); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	descriptor, err := account.NewDescriptor(&acc, chain, network)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	res := RespAccountDescriptor{Descriptor: account.FormatDescriptor(descriptor)}
+	if _, err := account.ParseDescriptor(res.Descriptor); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(data))
+	return nil
+}
+
+// ImportAccountDescriptor import account from the descriptor
+func ImportAccountDescriptor(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1]) //end func call
+	descriptorStr := args[0].Get("descriptor").String()
+	if lib.IsEmpty(descriptorStr) {
+		args[1].Set("error", "descriptor empty")
+		return nil
+	}
+
+	descriptor, err := account.ParseDescriptor(descriptorStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if _, err := getNetParams(descriptor.Network); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	var alias string
+	if aliasArg := args[0].Get("alias").String(); !lib.IsEmpty(aliasArg) {
+		alias = strings.ToLower(strings.TrimSpace(aliasArg))
+	}
+	acc, err := descriptor.Account(alias)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	rawAccount, err := json.Marshal(acc)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(rawAccount))
+	return nil
+}
@@ -1,10 +1,18 @@
 package base
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"syscall/js"
 
+	"github.com/bytom-community/wasm/bytom/blockchain/pseudohsm"
+	"github.com/bytom-community/wasm/bytom/blockchain/signers"
+	"github.com/bytom-community/wasm/bytom/common"
+	"github.com/bytom-community/wasm/bytom/crypto"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
 	"github.com/bytom-community/wasm/sdk/lib"
 )
 
@@ -13,6 +21,19 @@ type RespSignMessage struct {
 	Signature string `json:"signature"`
 }
 
+// RespSignStandardMessage is the response of SignStandardMessage
+type RespSignStandardMessage struct {
+	Signature      chainjson.HexBytes   `json:"signature"`
+	Pubkey         chainjson.HexBytes   `json:"pubkey"`
+	Address        string               `json:"address,omitempty"`
+	DerivationPath []chainjson.HexBytes `json:"derivation_path"`
+}
+
+// RespVerifyMessage is the response of VerifyMessage
+type RespVerifyMessage struct {
+	Valid bool `json:"valid"`
+}
+
 // SignMessage sign message
 func SignMessage(this js.Value, args []js.Value) interface{} {
 	defer lib.EndFunc(args[1])
@@ -24,6 +45,10 @@ func SignMessage(this js.Value, args []js.Value) interface{} {
 		return nil
 	}
 
+	if err := checkWatchOnly(args[0].Get("account").String(), keyJSON); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
 	signData, err := SignData(keyJSON, nil, []byte(message), password)
 	if err != nil {
 		args[1].Set("error", err.Error())
@@ -40,3 +65,194 @@ func SignMessage(this js.Value, args []js.Value) interface{} {
 	args[1].Set("data", string(j))
 	return nil
 }
+
+// VerifyMessage verify the signature of message by xpub with the derivation path or by public key
+func VerifyMessage(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	message := args[0].Get("message").String()
+	signatureStr := args[0].Get("signature").String()
+	if lib.IsEmpty(message) || lib.IsEmpty(signatureStr) {
+		args[1].Set("error", "args empty")
+		return nil
+	}
+
+	signature, err := hex.DecodeString(signatureStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	path, err := argsPath(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	pubkey, err := messagePubkey(args[0].Get("xpub").String(), path, args[0].Get("pubkey").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	ret := RespVerifyMessage{Valid: ed25519.Verify(pubkey, []byte(message), signature)}
+	j, err := json.Marshal(ret)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// SignStandardMessage sign the message in the standard format, the message is hashed with the
+// prefix for domain separation and signed by the key derived with the path or the account index
+// and address index, the derived address is checked if it is given.
+func SignStandardMessage(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	message := args[0].Get("message").String()
+	password := args[0].Get("password").String()
+	keyJSON := args[0].Get("key").String()
+	address := args[0].Get("address").String()
+	if lib.IsEmpty(message) || lib.IsEmpty(password) || lib.IsEmpty(keyJSON) {
+		args[1].Set("error", "args empty")
+		return nil
+	}
+
+	path, err := argsPath(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if !lib.IsEmpty(address) && path == nil {
+		args[1].Set("error", "path or accountIndex and addressIndex empty")
+		return nil
+	}
+
+	netParams, err := getNetParams(args[0].Get("network").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	if err := checkWatchOnly(args[0].Get("account").String(), keyJSON); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	key, err := pseudohsm.DecryptKey([]byte(keyJSON), password)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	derived, err := derivePubkey(key.XPub, path, netParams)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if !lib.IsEmpty(address) && address != derived.Address {
+		args[1].Set("error", "address does not match the key")
+		return nil
+	}
+
+	xprv := key.XPrv
+	if len(path) > 0 {
+		xprv = key.XPrv.Derive(path)
+	}
+	hash := signers.MessageHash([]byte(message))
+
+	ret := RespSignStandardMessage{
+		Signature:      xprv.Sign(hash[:]),
+		Pubkey:         derived.Pubkey,
+		DerivationPath: derived.DerivedPath,
+	}
+	if path != nil {
+		ret.Address = derived.Address
+	}
+	j, err := json.Marshal(ret)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// VerifyStandardMessage verify the signature of message in the standard format, if the address
+// is given, the signature must also correspond to the P2WPKH key of address.
+func VerifyStandardMessage(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	message := args[0].Get("message").String()
+	signatureStr := args[0].Get("signature").String()
+	address := args[0].Get("address").String()
+	if lib.IsEmpty(message) || lib.IsEmpty(signatureStr) {
+		args[1].Set("error", "args empty")
+		return nil
+	}
+
+	signature, err := hex.DecodeString(signatureStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	path, err := argsPath(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	pubkey, err := messagePubkey(args[0].Get("xpub").String(), path, args[0].Get("pubkey").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	hash := signers.MessageHash([]byte(message))
+	ret := RespVerifyMessage{Valid: ed25519.Verify(pubkey, hash[:], signature)}
+	if !lib.IsEmpty(address) {
+		netParams, err := getNetParams(args[0].Get("network").String())
+		if err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+
+		addr, err := common.DecodeAddress(address, netParams)
+		if err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+		if _, ok := addr.(*common.AddressWitnessPubKeyHash); !ok {
+			args[1].Set("error", "address is not P2WPKH")
+			return nil
+		}
+		ret.Valid = ret.Valid && bytes.Equal(addr.ScriptAddress(), crypto.Ripemd160(pubkey))
+	}
+
+	j, err := json.Marshal(ret)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// messagePubkey return the public key of message signer, the xpub is used first
+func messagePubkey(xpubStr string, path [][]byte, pubkeyStr string) (ed25519.PublicKey, error) {
+	if lib.IsEmpty(xpubStr) {
+		pubkey, err := hex.DecodeString(pubkeyStr)
+		if err != nil {
+			return nil, err
+		}
+		if len(pubkey) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid pubkey")
+		}
+		return ed25519.PublicKey(pubkey), nil
+	}
+
+	xpub, err := parseXPub(xpubStr)
+	if err != nil {
+		return nil, err
+	}
+	return xpub.Derive(path).PublicKey(), nil
+}
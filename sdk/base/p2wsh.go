@@ -0,0 +1,245 @@
+package base
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/consensus/segwit"
+	"github.com/bytom-community/wasm/bytom/crypto"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/sdk/lib"
+	vaporsegwit "github.com/bytom-community/wasm/vapor/consensus/segwit"
+	vaporvm "github.com/bytom-community/wasm/vapor/protocol/vm"
+)
+
+// RespVerifyP2WSH is the response of VerifyP2WSH
+type RespVerifyP2WSH struct {
+	Valid      bool               `json:"valid"`
+	Program    chainjson.HexBytes `json:"program,omitempty"`
+	ScriptHash chainjson.HexBytes `json:"script_hash,omitempty"`
+	TxID       string             `json:"tx_id,omitempty"`
+	GasLeft    int64              `json:"gas_left"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// decodeHexArguments decode the JSON array of hex arguments
+func decodeHexArguments(argumentsStr string) ([][]byte, error) {
+	var hexArgs []chainjson.HexBytes
+	if err := json.Unmarshal([]byte(argumentsStr), &hexArgs); err != nil {
+		return nil, err
+	}
+
+	arguments := make([][]byte, 0, len(hexArgs))
+	for _, arg := range hexArgs {
+		arguments = append(arguments, arg)
+	}
+	return arguments, nil
+}
+
+// WrapP2WSH wrap any program into the P2WSH program, return the script hash, the P2WSH program and address
+func WrapP2WSH(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	programStr := args[0].Get("program").String()
+	if lib.IsEmpty(programStr) {
+		args[1].Set("error", "program empty")
+		return nil
+	}
+
+	program, err := hex.DecodeString(programStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	dialect, err := checkVMDialect(args[0].Get("vm").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	resp, err := wrapP2WSH(dialect, program, args[0].Get("network").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// BuildP2WSHWitness build the witness arguments spending the P2WSH program, the program is appended after the clause arguments
+func BuildP2WSHWitness(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	programStr := args[0].Get("program").String()
+	if lib.IsEmpty(programStr) {
+		args[1].Set("error", "program empty")
+		return nil
+	}
+
+	program, err := hex.DecodeString(programStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	var clauseArgs [][]byte
+	if argumentsStr := args[0].Get("arguments").String(); !lib.IsEmpty(argumentsStr) {
+		if clauseArgs, err = decodeHexArguments(argumentsStr); err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+	}
+
+	witness := segwit.P2WSHWitness(program, clauseArgs)
+	if p2wshStr := args[0].Get("p2wsh_program").String(); !lib.IsEmpty(p2wshStr) {
+		p2wshProgram, err := hex.DecodeString(p2wshStr)
+		if err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+		if _, _, err := segwit.DecodeP2WSHWitness(p2wshProgram, witness); err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+	}
+
+	j, err := json.Marshal(newRespClauseArguments(witness))
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// VerifyP2WSH verify the witness spending the P2WSH program locally, the program revealed by the witness
+// must match the script hash, and is run by the VM with the clause arguments
+func VerifyP2WSH(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	p2wshStr := args[0].Get("p2wsh_program").String()
+	if lib.IsEmpty(p2wshStr) {
+		args[1].Set("error", "p2wsh_program empty")
+		return nil
+	}
+
+	p2wshProgram, err := hex.DecodeString(p2wshStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	argumentsStr := args[0].Get("arguments").String()
+	if lib.IsEmpty(argumentsStr) {
+		args[1].Set("error", "arguments empty")
+		return nil
+	}
+
+	witness, err := decodeHexArguments(argumentsStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	dialect, err := checkVMDialect(args[0].Get("vm").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	var proposal *ProposedTx
+	rawTx := args[0].Get("raw_transaction").String()
+	if proposalStr := args[0].Get("transaction").String(); !lib.IsEmpty(proposalStr) {
+		proposal = &ProposedTx{}
+		if err := json.Unmarshal([]byte(proposalStr), proposal); err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+	}
+
+	var position uint32
+	if p := args[0].Get("position"); p.Type() == js.TypeNumber {
+		position = uint32(p.Int())
+	}
+	var blockHeight uint64
+	if h := args[0].Get("block_height"); h.Type() == js.TypeNumber {
+		blockHeight = uint64(h.Int())
+	}
+	gasLimit := defaultGasLimit
+	if g := args[0].Get("gas_limit"); g.Type() == js.TypeNumber {
+		gasLimit = int64(g.Int())
+	}
+
+	resp, err := verifyP2WSH(dialect, p2wshProgram, witness, rawTx, proposal, position, blockHeight, gasLimit)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// verifyP2WSH check the program revealed by the witness against the P2WSH program, and run it by the VM.
+// The input at position of the transaction is simulated if the transaction is given, its control program
+// must be the P2WSH program. Otherwise the program is run without the transaction context, so the
+// introspection ops fail.
+func verifyP2WSH(dialect string, p2wshProgram []byte, witness [][]byte, rawTx string, proposal *ProposedTx, position uint32, blockHeight uint64, gasLimit int64) (*RespVerifyP2WSH, error) {
+	decode, convert := segwit.DecodeP2WSHWitness, segwit.ConvertP2SHProgram
+	if dialect == vmVapor {
+		decode, convert = vaporsegwit.DecodeP2WSHWitness, vaporsegwit.ConvertP2SHProgram
+	}
+
+	program, _, err := decode(p2wshProgram, witness)
+	if err != nil {
+		return &RespVerifyP2WSH{Error: err.Error()}, nil
+	}
+
+	resp := &RespVerifyP2WSH{Program: program, ScriptHash: crypto.Sha256(program)}
+	if proposal != nil || !lib.IsEmpty(rawTx) {
+		controlProgram, err := inputControlProgram(dialect, rawTx, proposal, position)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(controlProgram, p2wshProgram) {
+			resp.Error = fmt.Sprintf("control program %x of input %d is not the P2WSH program", controlProgram, position)
+			return resp, nil
+		}
+
+		simulated, err := simulateInput(dialect, rawTx, proposal, position, witness, blockHeight, gasLimit)
+		if err != nil {
+			return nil, err
+		}
+		resp.Valid, resp.TxID, resp.GasLeft, resp.Error = simulated.Valid, simulated.TxID, simulated.GasLeft, simulated.Error
+		return resp, nil
+	}
+
+	code, err := convert(p2wshProgram)
+	if err != nil {
+		return nil, err
+	}
+
+	if dialect == vmVapor {
+		resp.GasLeft, err = vaporvm.Verify(&vaporvm.Context{VMVersion: 1, Code: code, Arguments: witness}, gasLimit)
+	} else {
+		resp.GasLeft, err = vm.Verify(&vm.Context{VMVersion: 1, Code: code, Arguments: witness}, gasLimit)
+	}
+	resp.Valid = err == nil
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp, nil
+}
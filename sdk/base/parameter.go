@@ -1,18 +1,36 @@
 package base
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"math"
+	"strings"
 	"syscall/js"
+	"time"
 
 	"github.com/bytom-community/wasm/bytom/common"
 	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
 	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
 	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/math/checked"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
 	"github.com/bytom-community/wasm/bytom/protocol/vm"
 	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
 	"github.com/bytom-community/wasm/sdk/lib"
+	vaporcommon "github.com/bytom-community/wasm/vapor/common"
+	vaporconsensus "github.com/bytom-community/wasm/vapor/consensus"
+	vaporvm "github.com/bytom-community/wasm/vapor/protocol/vm"
+	vaporvmutil "github.com/bytom-community/wasm/vapor/protocol/vm/vmutil"
 )
 
+// bytomBlockSeconds is the target seconds per block of bytom, which is used
+// to estimate the block height of a time
+const bytomBlockSeconds = 150
+
 // ContractArgument for smart contract
 type ContractArgument struct {
 	Type    string          `json:"type"`
@@ -44,7 +62,74 @@ type AddressArgument struct {
 	Value string `json:"value"`
 }
 
+// AssetArgument is the asset argument for run contract, Value is the asset id or
+// the BTM alias
+type AssetArgument struct {
+	Value string `json:"value"`
+}
+
+// AmountArgument is the amount argument for run contract, the amount is Value
+// multiplied by 10 to the power of Decimals
+type AmountArgument struct {
+	Value    uint64 `json:"value"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// PubkeyArgument is the public key argument for run contract, which is derived
+// from the xpub by the path
+type PubkeyArgument struct {
+	XPub chainkd.XPub         `json:"xpub"`
+	Path []chainjson.HexBytes `json:"path"`
+}
+
+// TimeArgument is the time argument for run contract. The time is converted into
+// the unix timestamp, or the block height estimated from the reference block when
+// Unit is "block_height"
+type TimeArgument struct {
+	Value       time.Time `json:"value"`
+	Unit        string    `json:"unit"`
+	BlockHeight uint64    `json:"block_height"`
+	BlockTime   time.Time `json:"block_time"`
+	VM          string    `json:"vm"`
+}
+
+// ConvertContractArgs convert the contract argument whose raw data may be an
+// array of raw data, the arguments are returned in the order of array
+func ConvertContractArgs(arg ContractArgument) ([]*DataArgument, error) {
+	if !isArrayArg(arg) {
+		data, err := ConvertContractArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		return []*DataArgument{data}, nil
+	}
+
+	var rawDatas []json.RawMessage
+	if err := json.Unmarshal(arg.RawData, &rawDatas); err != nil {
+		return nil, err
+	}
+
+	results := make([]*DataArgument, 0, len(rawDatas))
+	for _, rawData := range rawDatas {
+		data, err := ConvertContractArg(ContractArgument{Type: arg.Type, RawData: rawData})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, data)
+	}
+	return results, nil
+}
+
+func isArrayArg(arg ContractArgument) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(arg.RawData), []byte("["))
+}
+
+// ConvertContractArg convert the contract argument into the data argument
 func ConvertContractArg(arg ContractArgument) (*DataArgument, error) {
+	if isArrayArg(arg) {
+		return nil, errors.New("array argument")
+	}
+
 	resultData := &DataArgument{}
 	switch arg.Type {
 	case "data":
@@ -110,6 +195,116 @@ func ConvertContractArg(arg ContractArgument) (*DataArgument, error) {
 		}
 		resultData.Value = program
 
+	case "asset":
+		data := &AssetArgument{}
+		if err := json.Unmarshal(arg.RawData, data); err != nil {
+			return nil, err
+		}
+
+		if data.Value == consensus.BTMAlias {
+			resultData.Value = consensus.BTMAssetID.Bytes()
+			break
+		}
+
+		assetID := bc.AssetID{}
+		if err := assetID.UnmarshalText([]byte(data.Value)); err != nil {
+			return nil, errors.New("bad asset id")
+		}
+		resultData.Value = assetID.Bytes()
+
+	case "amount":
+		data := &AmountArgument{}
+		if err := json.Unmarshal(arg.RawData, data); err != nil {
+			return nil, err
+		}
+
+		amount, ok := data.Value, true
+		for i := uint8(0); i < data.Decimals && ok; i++ {
+			amount, ok = checked.MulUint64(amount, 10)
+		}
+		if !ok || amount > math.MaxInt64 {
+			return nil, errors.New("amount overflow")
+		}
+		resultData.Value = vm.Int64Bytes(int64(amount))
+
+	case "pubkey":
+		data := &PubkeyArgument{}
+		if err := json.Unmarshal(arg.RawData, data); err != nil {
+			return nil, err
+		}
+
+		if data.XPub == (chainkd.XPub{}) {
+			return nil, errors.New("xpub empty")
+		}
+
+		path := make([][]byte, 0, len(data.Path))
+		for _, p := range data.Path {
+			path = append(path, p)
+		}
+		resultData.Value = chainjson.HexBytes(data.XPub.Derive(path).PublicKey())
+
+	case "signature":
+		data := &DataArgument{}
+		if err := json.Unmarshal(arg.RawData, data); err != nil {
+			return nil, err
+		}
+
+		if len(data.Value) != ed25519.SignatureSize {
+			return nil, errors.New("bad signature size")
+		}
+		resultData.Value = data.Value
+
+	case "sha3":
+		data := &DataArgument{}
+		if err := json.Unmarshal(arg.RawData, data); err != nil {
+			return nil, err
+		}
+		// crypto.Sha256 is SHA3-256 in bytom despite its name, don't replace it by SHA-256
+		resultData.Value = crypto.Sha256(data.Value)
+
+	case "sha256":
+		data := &DataArgument{}
+		if err := json.Unmarshal(arg.RawData, data); err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(data.Value)
+		resultData.Value = hash[:]
+
+	case "time":
+		data := &TimeArgument{}
+		if err := json.Unmarshal(arg.RawData, data); err != nil {
+			return nil, err
+		}
+
+		value, err := convertTime(data)
+		if err != nil {
+			return nil, err
+		}
+		resultData.Value = vm.Int64Bytes(value)
+
+	case "vapor_address":
+		data := &AddressArgument{}
+		if err := json.Unmarshal(arg.RawData, data); err != nil {
+			return nil, err
+		}
+
+		program, err := vaporAddressProgram(data.Value)
+		if err != nil {
+			return nil, err
+		}
+		resultData.Value = program
+
+	case "vapor_program":
+		data := &DataArgument{}
+		if err := json.Unmarshal(arg.RawData, data); err != nil {
+			return nil, err
+		}
+
+		if _, err := vaporvm.ParseProgram(data.Value); err != nil {
+			return nil, err
+		}
+		resultData.Value = data.Value
+
 	default:
 		return nil, errors.New("bad argument type")
 	}
@@ -117,6 +312,66 @@ func ConvertContractArg(arg ContractArgument) (*DataArgument, error) {
 	return resultData, nil
 }
 
+// convertTime convert the time argument into the unix timestamp or the block height
+func convertTime(data *TimeArgument) (int64, error) {
+	switch data.Unit {
+	case "", "timestamp":
+		return data.Value.Unix(), nil
+
+	case "block_height":
+		if data.BlockTime.IsZero() {
+			return 0, errors.New("block_time empty")
+		}
+		if data.Value.Before(data.BlockTime) {
+			return 0, errors.New("time before the block time")
+		}
+
+		dialect, err := checkVMDialect(data.VM)
+		if err != nil {
+			return 0, err
+		}
+
+		interval := time.Duration(bytomBlockSeconds) * time.Second
+		if dialect == vmVapor {
+			interval = time.Duration(vaporconsensus.ActiveNetParams.BlockTimeInterval) * time.Millisecond
+		}
+
+		blocks := int64(data.Value.Sub(data.BlockTime) / interval)
+		height, ok := checked.AddInt64(int64(data.BlockHeight), blocks)
+		if !ok || height < 0 {
+			return 0, errors.New("block height overflow")
+		}
+		return height, nil
+
+	default:
+		return 0, errors.New("bad time unit")
+	}
+}
+
+// vaporAddressProgram return the control program of the vapor address on any network
+func vaporAddressProgram(addr string) ([]byte, error) {
+	for _, netParams := range vaporconsensus.NetParams {
+		if !strings.HasPrefix(addr, netParams.Bech32HRPSegwit+"1") {
+			continue
+		}
+
+		address, err := vaporcommon.DecodeAddress(addr, &netParams)
+		if err != nil {
+			return nil, err
+		}
+
+		switch address.(type) {
+		case *vaporcommon.AddressWitnessPubKeyHash:
+			return vaporvmutil.P2WPKHProgram(address.ScriptAddress())
+		case *vaporcommon.AddressWitnessScriptHash:
+			return vaporvmutil.P2WSHProgram(address.ScriptAddress())
+		default:
+			return nil, errors.New("bad address type")
+		}
+	}
+	return nil, errors.New("bad address format")
+}
+
 // ConvertArgument convert arguments
 func ConvertArgument(this js.Value, args []js.Value) interface{} {
 	defer lib.EndFunc(args[1]) //end func call
@@ -127,7 +382,7 @@ func ConvertArgument(this js.Value, args []js.Value) interface{} {
 	}
 
 	rawDataStr := args[0].Get("raw_data").String()
-	if lib.IsEmpty(typ) {
+	if lib.IsEmpty(rawDataStr) {
 		args[1].Set("error", "raw_data empty")
 		return nil
 	}
@@ -143,13 +398,18 @@ func ConvertArgument(this js.Value, args []js.Value) interface{} {
 		Type:    typ,
 		RawData: rawData,
 	}
-	dataArgument, err := ConvertContractArg(arg)
+	dataArguments, err := ConvertContractArgs(arg)
 	if err != nil {
 		args[1].Set("error", err.Error())
 		return nil
 	}
 
-	data, _ := json.Marshal(dataArgument)
+	var data []byte
+	if isArrayArg(arg) {
+		data, _ = json.Marshal(dataArguments)
+	} else {
+		data, _ = json.Marshal(dataArguments[0])
+	}
 	args[1].Set("data", string(data))
 	return nil
 }
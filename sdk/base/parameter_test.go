@@ -0,0 +1,152 @@
+package base
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	vaporcommon "github.com/bytom-community/wasm/vapor/common"
+	vaporconsensus "github.com/bytom-community/wasm/vapor/consensus"
+	vaporvmutil "github.com/bytom-community/wasm/vapor/protocol/vm/vmutil"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestConvertContractArg(t *testing.T) {
+	_, xpub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xpubJSON, err := json.Marshal(xpub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubHash := bytes.Repeat([]byte{1}, 20)
+	vaporAddress, err := vaporcommon.NewAddressWitnessPubKeyHash(pubHash, &vaporconsensus.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vaporProgram, err := vaporvmutil.P2WPKHProgram(pubHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	bytomTime := blockTime.Add(10 * bytomBlockSeconds * time.Second)
+	vaporTime := blockTime.Add(10 * time.Duration(vaporconsensus.ActiveNetParams.BlockTimeInterval) * time.Millisecond)
+	timeArg := func(value time.Time, dialect string) string {
+		return fmt.Sprintf(`{"value": %q, "unit": "block_height", "block_height": 100, "block_time": %q, "vm": %q}`,
+			value.Format(time.RFC3339), blockTime.Format(time.RFC3339), dialect)
+	}
+
+	cases := []struct {
+		desc    string
+		typ     string
+		rawData string
+		want    []byte
+		wantErr string
+	}{
+		{desc: "BTM alias", typ: "asset", rawData: `{"value": "BTM"}`, want: consensus.BTMAssetID.Bytes()},
+		{desc: "asset id", typ: "asset", rawData: `{"value": "` + strings.Repeat("f", 64) + `"}`, want: bytes.Repeat([]byte{0xff}, 32)},
+		{desc: "bad asset id", typ: "asset", rawData: `{"value": "btm"}`, wantErr: "bad asset id"},
+		{desc: "max amount", typ: "amount", rawData: `{"value": 9223372036854775807}`, want: vm.Int64Bytes(math.MaxInt64)},
+		{desc: "max amount with decimals", typ: "amount", rawData: `{"value": 922337203685477580, "decimals": 1}`, want: vm.Int64Bytes(9223372036854775800)},
+		{desc: "amount over int64", typ: "amount", rawData: `{"value": 9223372036854775808}`, wantErr: "amount overflow"},
+		{desc: "amount with decimals over int64", typ: "amount", rawData: `{"value": 922337203685477581, "decimals": 1}`, wantErr: "amount overflow"},
+		{desc: "amount with decimals over uint64", typ: "amount", rawData: `{"value": 1, "decimals": 20}`, wantErr: "amount overflow"},
+		{desc: "pubkey", typ: "pubkey", rawData: `{"xpub": ` + string(xpubJSON) + `, "path": ["01", "02"]}`, want: xpub.Derive([][]byte{{1}, {2}}).PublicKey()},
+		{desc: "pubkey without path", typ: "pubkey", rawData: `{"xpub": ` + string(xpubJSON) + `}`, want: xpub.PublicKey()},
+		{desc: "pubkey without xpub", typ: "pubkey", rawData: `{"path": ["01"]}`, wantErr: "xpub empty"},
+		{desc: "signature", typ: "signature", rawData: `{"value": "` + strings.Repeat("01", 64) + `"}`, want: bytes.Repeat([]byte{1}, 64)},
+		{desc: "short signature", typ: "signature", rawData: `{"value": "` + strings.Repeat("01", 63) + `"}`, wantErr: "bad signature size"},
+		{desc: "long signature", typ: "signature", rawData: `{"value": "` + strings.Repeat("01", 65) + `"}`, wantErr: "bad signature size"},
+		{desc: "sha3", typ: "sha3", rawData: `{"value": "616263"}`, want: mustDecodeHex("3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532")},
+		{desc: "sha256", typ: "sha256", rawData: `{"value": "616263"}`, want: mustDecodeHex("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")},
+		{desc: "timestamp", typ: "time", rawData: fmt.Sprintf(`{"value": %q}`, blockTime.Format(time.RFC3339)), want: vm.Int64Bytes(blockTime.Unix())},
+		{desc: "bytom block height", typ: "time", rawData: timeArg(bytomTime, ""), want: vm.Int64Bytes(110)},
+		{desc: "bytom block height of bytom vm", typ: "time", rawData: timeArg(bytomTime, "bytom"), want: vm.Int64Bytes(110)},
+		{desc: "vapor block height", typ: "time", rawData: timeArg(vaporTime, "vapor"), want: vm.Int64Bytes(110)},
+		{desc: "block height of block time", typ: "time", rawData: timeArg(blockTime, "vapor"), want: vm.Int64Bytes(100)},
+		{desc: "time before the block time", typ: "time", rawData: timeArg(blockTime.Add(-time.Second), ""), wantErr: "time before the block time"},
+		{desc: "block height without block time", typ: "time", rawData: fmt.Sprintf(`{"value": %q, "unit": "block_height"}`, blockTime.Format(time.RFC3339)), wantErr: "block_time empty"},
+		{desc: "block height of bad vm", typ: "time", rawData: timeArg(bytomTime, "eth"), wantErr: "bad vm"},
+		{desc: "bad time unit", typ: "time", rawData: fmt.Sprintf(`{"value": %q, "unit": "day"}`, blockTime.Format(time.RFC3339)), wantErr: "bad time unit"},
+		{desc: "vapor address", typ: "vapor_address", rawData: `{"value": "` + vaporAddress.EncodeAddress() + `"}`, want: vaporProgram},
+		{desc: "bytom address as vapor address", typ: "vapor_address", rawData: `{"value": "bm1qqyqszqgpqyqszqgpqyqszqgpqyqszqgp2tfpnh"}`, wantErr: "bad address format"},
+		{desc: "vapor program", typ: "vapor_program", rawData: `{"value": "` + hex.EncodeToString(vaporProgram) + `"}`, want: vaporProgram},
+		{desc: "truncated vapor program", typ: "vapor_program", rawData: `{"value": "4c"}`, wantErr: "unexpected end of program"},
+		{desc: "array", typ: "integer", rawData: `[{"value": 1}]`, wantErr: "array argument"},
+		{desc: "bad type", typ: "float", rawData: `{"value": 1}`, wantErr: "bad argument type"},
+	}
+
+	for _, c := range cases {
+		got, err := ConvertContractArg(ContractArgument{Type: c.typ, RawData: json.RawMessage(c.rawData)})
+		if c.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("%s: got error %v, want %q", c.desc, err, c.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.desc, err)
+			continue
+		}
+		if !bytes.Equal(got.Value, c.want) {
+			t.Errorf("%s: got %x, want %x", c.desc, got.Value, c.want)
+		}
+	}
+}
+
+func TestConvertContractArgs(t *testing.T) {
+	cases := []struct {
+		desc    string
+		rawData string
+		want    [][]byte
+		wantErr bool
+	}{
+		{desc: "single value", rawData: `{"value": 1}`, want: [][]byte{vm.Int64Bytes(1)}},
+		{desc: "array", rawData: `[{"value": 1}, {"value": 2}]`, want: [][]byte{vm.Int64Bytes(1), vm.Int64Bytes(2)}},
+		{desc: "array with spaces", rawData: ` [{"value": 3}]`, want: [][]byte{vm.Int64Bytes(3)}},
+		{desc: "empty array", rawData: `[]`, want: [][]byte{}},
+		{desc: "array with bad element", rawData: `[{"value": 1}, {"value": "a"}]`, wantErr: true},
+	}
+
+	for _, c := range cases {
+		got, err := ConvertContractArgs(ContractArgument{Type: "integer", RawData: json.RawMessage(c.rawData)})
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: got no error", c.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.desc, err)
+			continue
+		}
+
+		if len(got) != len(c.want) {
+			t.Errorf("%s: got %d arguments, want %d", c.desc, len(got), len(c.want))
+			continue
+		}
+		for i, arg := range got {
+			if !bytes.Equal(arg.Value, c.want[i]) {
+				t.Errorf("%s: argument %d got %x, want %x", c.desc, i, arg.Value, c.want[i])
+			}
+		}
+	}
+}
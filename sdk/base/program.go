@@ -0,0 +1,65 @@
+package base
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/blockchain"
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/sdk/lib"
+)
+
+// RespProgramAddress is the response of GetAddressFromControlProgram
+type RespProgramAddress struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+}
+
+// getNetParams return the net params by network name, default is the active one
+func getNetParams(network string) (*consensus.Params, error) {
+	if lib.IsEmpty(network) {
+		return &consensus.ActiveNetParams, nil
+	}
+
+	netParams, ok := consensus.NetParams[network]
+	if !ok {
+		return nil, errors.New("bad network")
+	}
+	return &netParams, nil
+}
+
+// GetAddressFromControlProgram convert control program to address
+func GetAddressFromControlProgram(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1]) //end func call
+	programStr := args[0].Get("control_program").String()
+	if lib.IsEmpty(programStr) {
+		args[1].Set("error", "control_program empty")
+		return nil
+	}
+
+	program, err := hex.DecodeString(programStr)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	netParams, err := getNetParams(args[0].Get("network").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	res := RespProgramAddress{
+		Address: blockchain.GetAddressFromControlProgram(program, netParams),
+		Type:    blockchain.GetControlProgramType(program),
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(data))
+	return nil
+}
@@ -0,0 +1,131 @@
+package base
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/signers"
+	"github.com/bytom-community/wasm/bytom/common"
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+	"github.com/bytom-community/wasm/sdk/lib"
+)
+
+// DerivedPubkey is the public key derived from xpub by the path
+type DerivedPubkey struct {
+	XPub           chainkd.XPub         `json:"xpub"`
+	Pubkey         chainjson.HexBytes   `json:"pubkey"`
+	PubkeyHash     chainjson.HexBytes   `json:"pubkey_hash"`
+	ControlProgram chainjson.HexBytes   `json:"control_program"`
+	Address        string               `json:"address"`
+	DerivedPath    []chainjson.HexBytes `json:"derived_path"`
+}
+
+func parseXPub(xpubStr string) (*chainkd.XPub, error) {
+	if lib.IsEmpty(xpubStr) || len(xpubStr) != 128 {
+		return nil, errors.New("invalid xpub")
+	}
+
+	xpub := &chainkd.XPub{}
+	if err := xpub.UnmarshalText([]byte(xpubStr)); err != nil {
+		return nil, err
+	}
+	return xpub, nil
+}
+
+// argsPath return the derivation path given by the path or the account index and
+// address index of args, nil is returned if both of them are empty.
+func argsPath(arg js.Value) ([][]byte, error) {
+	if pathStr := arg.Get("path").String(); !lib.IsEmpty(pathStr) {
+		var hexPath []chainjson.HexBytes
+		if err := json.Unmarshal([]byte(pathStr), &hexPath); err != nil {
+			return nil, err
+		}
+
+		path := make([][]byte, 0, len(hexPath))
+		for _, p := range hexPath {
+			path = append(path, p)
+		}
+		return path, nil
+	}
+
+	accountIndex, addressIndex := arg.Get("accountIndex"), arg.Get("addressIndex")
+	if accountIndex.Type() != js.TypeNumber || addressIndex.Type() != js.TypeNumber {
+		return nil, nil
+	}
+	signer := &signers.Signer{KeyIndex: uint64(accountIndex.Int())}
+	return signers.Path(signer, signers.AccountKeySpace, uint64(addressIndex.Int())), nil
+}
+
+func derivePubkey(xpub chainkd.XPub, path [][]byte, netParams *consensus.Params) (*DerivedPubkey, error) {
+	derivedXPub := xpub.Derive(path)
+	pubkey := derivedXPub.PublicKey()
+	pubHash := crypto.Ripemd160(pubkey)
+
+	address, err := common.NewAddressWitnessPubKeyHash(pubHash, netParams)
+	if err != nil {
+		return nil, err
+	}
+
+	control, err := vmutil.P2WPKHProgram(pubHash)
+	if err != nil {
+		return nil, err
+	}
+
+	derivedPath := make([]chainjson.HexBytes, 0, len(path))
+	for _, p := range path {
+		derivedPath = append(derivedPath, p)
+	}
+	return &DerivedPubkey{
+		XPub:           derivedXPub,
+		Pubkey:         chainjson.HexBytes(pubkey),
+		PubkeyHash:     pubHash,
+		ControlProgram: control,
+		Address:        address.EncodeAddress(),
+		DerivedPath:    derivedPath,
+	}, nil
+}
+
+// DerivePubkey derive the public key of xpub by the path or the account index and address index
+func DerivePubkey(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1]) //end func call
+	xpub, err := parseXPub(args[0].Get("xpub").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	netParams, err := getNetParams(args[0].Get("network").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	path, err := argsPath(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if path == nil {
+		args[1].Set("error", "path or accountIndex and addressIndex empty")
+		return nil
+	}
+
+	res, err := derivePubkey(*xpub, path, netParams)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(data))
+	return nil
+}
@@ -0,0 +1,247 @@
+package base
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/pseudohsm"
+	"github.com/bytom-community/wasm/bytom/blockchain/remotesigner"
+	"github.com/bytom-community/wasm/bytom/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/sdk/lib"
+)
+
+// the type of signer
+const (
+	signerCallback = "callback"
+	signerRemote   = "remote"
+)
+
+// signerRegistry routes each key of template to the registered signer owning its xpub,
+// only the signers without secret are registered, the keys of local keystore are
+// decrypted for each signing call and zeroed after it
+var signerRegistry = txbuilder.NewSignerRegistry()
+
+// RespRegisterSigner is the response of RegisterSigner
+type RespRegisterSigner struct {
+	XPubs []chainkd.XPub `json:"xpubs"`
+}
+
+// callbackSigner signs by the JS host callback, such as the hardware wallet
+// connected by WebUSB or WebHID. The callback is called with the json string of
+// remotesigner.SignRequest, and returns the hex signature or a promise of it.
+type callbackSigner struct {
+	xpubs    []chainkd.XPub
+	callback js.Value
+}
+
+func (s *callbackSigner) XPubs() []chainkd.XPub {
+	return s.xpubs
+}
+
+// Sign wait the callback to sign, it must not be called in the goroutine of js callback.
+func (s *callbackSigner) Sign(_ context.Context, xpub chainkd.XPub, path [][]byte, hash [32]byte) ([]byte, error) {
+	req := &remotesigner.SignRequest{XPub: xpub, DerivationPath: make([]chainjson.HexBytes, 0, len(path)), Hash: hash[:]}
+	for _, p := range path {
+		req.DerivationPath = append(req.DerivationPath, p)
+	}
+
+	j, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := awaitPromise(s.callback.Invoke(string(j)))
+	if err != nil {
+		return nil, err
+	}
+	if result.Type() != js.TypeString {
+		return nil, errors.New("signature of callback is not string")
+	}
+	return hex.DecodeString(result.String())
+}
+
+// awaitPromise wait the promise to be settled, the value not a promise is returned directly
+func awaitPromise(v js.Value) (js.Value, error) {
+	if v.Type() != js.TypeObject || v.Get("then").Type() != js.TypeFunction {
+		return v, nil
+	}
+
+	var (
+		result js.Value
+		err    error
+	)
+	done := make(chan struct{})
+	onResolve := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if len(args) > 0 {
+			result = args[0]
+		}
+		close(done)
+		return nil
+	})
+	onReject := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		err = errors.New("callback rejected")
+		if len(args) > 0 {
+			err = errors.New(args[0].String())
+		}
+		close(done)
+		return nil
+	})
+	defer onResolve.Release()
+	defer onReject.Release()
+
+	v.Call("then", onResolve, onReject)
+	<-done
+	return result, err
+}
+
+// parseXPubs parse the json array of xpub
+func parseXPubs(xpubsJSON string) ([]chainkd.XPub, error) {
+	var xpubs []chainkd.XPub
+	if err := json.Unmarshal([]byte(xpubsJSON), &xpubs); err != nil {
+		return nil, err
+	}
+	if len(xpubs) == 0 {
+		return nil, errors.New("xpubs empty")
+	}
+	return xpubs, nil
+}
+
+// newSigner create the signer by the type of args
+func newSigner(arg js.Value) (txbuilder.Signer, error) {
+	switch signerType := arg.Get("type").String(); signerType {
+	case signerCallback:
+		callback := arg.Get("sign")
+		if callback.Type() != js.TypeFunction {
+			return nil, errors.New("sign callback empty")
+		}
+
+		xpubs, err := parseXPubs(arg.Get("xpubs").String())
+		if err != nil {
+			return nil, err
+		}
+		return &callbackSigner{xpubs: xpubs, callback: callback}, nil
+
+	case signerRemote:
+		url := arg.Get("url").String()
+		if lib.IsEmpty(url) {
+			return nil, errors.New("url empty")
+		}
+
+		xpubs, err := parseXPubs(arg.Get("xpubs").String())
+		if err != nil {
+			return nil, err
+		}
+		return remotesigner.NewClient(url, xpubs, nil), nil
+
+	default:
+		return nil, errors.New("bad signer type " + signerType)
+	}
+}
+
+// RegisterSigner register the signer of JS host callback or remote HTTP signer
+func RegisterSigner(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	signer, err := newSigner(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	if err := signerRegistry.Register(signer); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(RespRegisterSigner{XPubs: signer.XPubs()})
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// UnregisterSigner unregister the signer owning the xpub
+func UnregisterSigner(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	xpub, err := parseXPub(args[0].Get("xpub").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	signerRegistry.Unregister(*xpub)
+	args[1].Set("data", "")
+	return nil
+}
+
+// keysOrRegistrySignFunc return the SignFunc signing with the decrypted keys of the
+// call, the other keys are routed to the registered signers
+func keysOrRegistrySignFunc(keys map[chainkd.XPub]*pseudohsm.XKey) txbuilder.SignFunc {
+	keysFn, registryFn := keysSignFunc(keys), signerRegistry.SignFunc()
+	return func(ctx context.Context, xpub chainkd.XPub, path [][]byte, data [32]byte, auth string) ([]byte, error) {
+		if _, ok := keys[xpub]; ok {
+			return keysFn(ctx, xpub, path, data, auth)
+		}
+		return registryFn(ctx, xpub, path, data, auth)
+	}
+}
+
+// SignTemplateWithSigners sign the transaction template by the local keystore keys and the
+// registered signers, each key is routed to the signer owning its xpub. The keystore keys are
+// decrypted for this call only and zeroed after it. The signers may wait for the hardware or
+// network, so it is signed in a new goroutine and the result is returned asynchronously. The
+// transaction of other id than the tx_id of the confirmed summary is refused if the tx_id is given.
+func SignTemplateWithSigners(this js.Value, args []js.Value) interface{} {
+	transaction := args[0].Get("transaction").String()
+	if lib.IsEmpty(transaction) {
+		args[1].Set("error", "transaction empty")
+		lib.EndFunc(args[1])
+		return nil
+	}
+
+	tpl := &txbuilder.Template{}
+	if err := json.Unmarshal([]byte(transaction), tpl); err != nil {
+		args[1].Set("error", err.Error())
+		lib.EndFunc(args[1])
+		return nil
+	}
+	if err := checkTemplateTxID(args[0].Get("tx_id").String(), tpl); err != nil {
+		args[1].Set("error", err.Error())
+		lib.EndFunc(args[1])
+		return nil
+	}
+
+	keysJSON, password := args[0].Get("keys").String(), args[0].Get("password").String()
+	accountJSON := args[0].Get("account").String()
+	go func() {
+		defer lib.EndFunc(args[1])
+		keys := make(map[chainkd.XPub]*pseudohsm.XKey)
+		if !lib.IsEmpty(keysJSON) {
+			var err error
+			if keys, err = decryptKeys(accountJSON, keysJSON, txbuilder.RequiredXPubs([]*txbuilder.Template{tpl}), password); err != nil {
+				args[1].Set("error", err.Error())
+				return
+			}
+		}
+		defer zeroKeys(keys)
+
+		if err := txbuilder.Sign(context.Background(), tpl, "", keysOrRegistrySignFunc(keys)); err != nil {
+			args[1].Set("error", err.Error())
+			return
+		}
+
+		j, err := json.Marshal(newRespSignTemplate(tpl))
+		if err != nil {
+			args[1].Set("error", err.Error())
+			return
+		}
+		args[1].Set("data", string(j))
+	}()
+	return nil
+}
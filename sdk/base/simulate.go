@@ -0,0 +1,339 @@
+package base
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
+	"github.com/bytom-community/wasm/bytom/protocol/validation"
+	"github.com/bytom-community/wasm/sdk/lib"
+	vaporbc "github.com/bytom-community/wasm/vapor/protocol/bc"
+	vaportypes "github.com/bytom-community/wasm/vapor/protocol/bc/types"
+	vaporvalidation "github.com/bytom-community/wasm/vapor/protocol/validation"
+)
+
+var errBadProposedTx = errors.New("bad proposed transaction")
+
+// ProposedInput is the input of the proposed transaction to simulate
+type ProposedInput struct {
+	Type            string             `json:"type"`
+	SourceID        chainjson.HexBytes `json:"source_id"`
+	SourcePosition  uint64             `json:"source_position"`
+	AssetID         chainjson.HexBytes `json:"asset_id"`
+	Amount          uint64             `json:"amount"`
+	ControlProgram  chainjson.HexBytes `json:"control_program"`
+	Nonce           chainjson.HexBytes `json:"nonce"`
+	IssuanceProgram chainjson.HexBytes `json:"issuance_program"`
+	AssetDefinition chainjson.HexBytes `json:"asset_definition"`
+	Vote            chainjson.HexBytes `json:"vote"`
+}
+
+// ProposedOutput is the output of the proposed transaction to simulate
+type ProposedOutput struct {
+	Type           string             `json:"type"`
+	AssetID        chainjson.HexBytes `json:"asset_id"`
+	Amount         uint64             `json:"amount"`
+	ControlProgram chainjson.HexBytes `json:"control_program"`
+	Vote           chainjson.HexBytes `json:"vote"`
+}
+
+// ProposedTx is the transaction to simulate, which is not required to be signed
+type ProposedTx struct {
+	Version   uint64            `json:"version"`
+	TimeRange uint64            `json:"time_range"`
+	Inputs    []*ProposedInput  `json:"inputs"`
+	Outputs   []*ProposedOutput `json:"outputs"`
+}
+
+// RespSimulateInput is the result of evaluating the program of input
+type RespSimulateInput struct {
+	TxID    string `json:"tx_id"`
+	Valid   bool   `json:"valid"`
+	GasLeft int64  `json:"gas_left"`
+	Error   string `json:"error,omitempty"`
+}
+
+func hash32(b []byte, name string) ([32]byte, error) {
+	var h [32]byte
+	if len(b) != 32 {
+		return h, errors.WithDetailf(errBadProposedTx, "%s length %d", name, len(b))
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+func (p *ProposedTx) txVersion() uint64 {
+	if p.Version == 0 {
+		return 1
+	}
+	return p.Version
+}
+
+func (p *ProposedTx) bytomTxData() (*types.TxData, error) {
+	txData := &types.TxData{Version: p.txVersion(), TimeRange: p.TimeRange}
+	for i, in := range p.Inputs {
+		switch in.Type {
+		case "", "spend":
+			sourceID, err := hash32(in.SourceID, "source_id")
+			if err != nil {
+				return nil, err
+			}
+			assetID, err := hash32(in.AssetID, "asset_id")
+			if err != nil {
+				return nil, err
+			}
+			txData.Inputs = append(txData.Inputs, types.NewSpendInput(nil, bc.NewHash(sourceID), bc.NewAssetID(assetID), in.Amount, in.SourcePosition, in.ControlProgram))
+
+		case "issue":
+			txData.Inputs = append(txData.Inputs, types.NewIssuanceInput(in.Nonce, in.Amount, in.IssuanceProgram, nil, in.AssetDefinition))
+
+		default:
+			return nil, errors.WithDetailf(errBadProposedTx, "input %d type %s", i, in.Type)
+		}
+	}
+
+	for i, out := range p.Outputs {
+		if out.Type != "" && out.Type != "control" {
+			return nil, errors.WithDetailf(errBadProposedTx, "output %d type %s", i, out.Type)
+		}
+		assetID, err := hash32(out.AssetID, "asset_id")
+		if err != nil {
+			return nil, err
+		}
+		txData.Outputs = append(txData.Outputs, types.NewTxOutput(bc.NewAssetID(assetID), out.Amount, out.ControlProgram))
+	}
+	return txData, nil
+}
+
+func (p *ProposedTx) vaporTxData() (*vaportypes.TxData, error) {
+	txData := &vaportypes.TxData{Version: p.txVersion(), TimeRange: p.TimeRange}
+	for i, in := range p.Inputs {
+		sourceID, err := hash32(in.SourceID, "source_id")
+		if err != nil {
+			return nil, err
+		}
+		assetID, err := hash32(in.AssetID, "asset_id")
+		if err != nil {
+			return nil, err
+		}
+
+		switch in.Type {
+		case "", "spend":
+			txData.Inputs = append(txData.Inputs, vaportypes.NewSpendInput(nil, vaporbc.NewHash(sourceID), vaporbc.NewAssetID(assetID), in.Amount, in.SourcePosition, in.ControlProgram))
+
+		case "veto":
+			txData.Inputs = append(txData.Inputs, vaportypes.NewVetoInput(nil, vaporbc.NewHash(sourceID), vaporbc.NewAssetID(assetID), in.Amount, in.SourcePosition, in.ControlProgram, in.Vote))
+
+		default:
+			return nil, errors.WithDetailf(errBadProposedTx, "input %d type %s", i, in.Type)
+		}
+	}
+
+	for i, out := range p.Outputs {
+		assetID, err := hash32(out.AssetID, "asset_id")
+		if err != nil {
+			return nil, err
+		}
+
+		switch out.Type {
+		case "", "control":
+			txData.Outputs = append(txData.Outputs, vaportypes.NewIntraChainOutput(vaporbc.NewAssetID(assetID), out.Amount, out.ControlProgram))
+
+		case "vote":
+			txData.Outputs = append(txData.Outputs, vaportypes.NewVoteOutput(vaporbc.NewAssetID(assetID), out.Amount, out.ControlProgram, out.Vote))
+
+		case "crosschain":
+			txData.Outputs = append(txData.Outputs, vaportypes.NewCrossChainOutput(vaporbc.NewAssetID(assetID), out.Amount, out.ControlProgram))
+
+		default:
+			return nil, errors.WithDetailf(errBadProposedTx, "output %d type %s", i, out.Type)
+		}
+	}
+	return txData, nil
+}
+
+// bytomTxData returns the bytom transaction of the proposal, or of the raw
+// transaction if proposal is nil
+func bytomTxData(rawTx string, proposal *ProposedTx) (*types.TxData, error) {
+	if proposal != nil {
+		return proposal.bytomTxData()
+	}
+
+	txData := &types.TxData{}
+	if err := txData.UnmarshalText([]byte(rawTx)); err != nil {
+		return nil, err
+	}
+	return txData, nil
+}
+
+// vaporTxData returns the vapor transaction of the proposal, or of the raw
+// transaction if proposal is nil
+func vaporTxData(rawTx string, proposal *ProposedTx) (*vaportypes.TxData, error) {
+	if proposal != nil {
+		return proposal.vaporTxData()
+	}
+
+	txData := &vaportypes.TxData{}
+	if err := txData.UnmarshalText([]byte(rawTx)); err != nil {
+		return nil, err
+	}
+	return txData, nil
+}
+
+// inputControlProgram returns the control program of input at position of
+// the proposal, or of the raw transaction if proposal is nil
+func inputControlProgram(dialect, rawTx string, proposal *ProposedTx, position uint32) ([]byte, error) {
+	if proposal != nil {
+		if int(position) >= len(proposal.Inputs) {
+			return nil, errors.WithDetailf(validation.ErrBadInputIdx, "position %d, %d inputs", position, len(proposal.Inputs))
+		}
+		return proposal.Inputs[position].ControlProgram, nil
+	}
+
+	if dialect == vmVapor {
+		txData, err := vaporTxData(rawTx, nil)
+		if err != nil {
+			return nil, err
+		}
+		if int(position) >= len(txData.Inputs) {
+			return nil, errors.WithDetailf(vaporvalidation.ErrBadInputIdx, "position %d, %d inputs", position, len(txData.Inputs))
+		}
+		return txData.Inputs[position].ControlProgram(), nil
+	}
+
+	txData, err := bytomTxData(rawTx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if int(position) >= len(txData.Inputs) {
+		return nil, errors.WithDetailf(validation.ErrBadInputIdx, "position %d, %d inputs", position, len(txData.Inputs))
+	}
+	return txData.Inputs[position].ControlProgram(), nil
+}
+
+// parseTxArgs returns the raw transaction and the proposed transaction of the
+// request, one of them is required
+func parseTxArgs(arg js.Value) (string, *ProposedTx, error) {
+	rawTx := arg.Get("raw_transaction").String()
+	proposalStr := arg.Get("transaction").String()
+	if lib.IsEmpty(rawTx) && lib.IsEmpty(proposalStr) {
+		return "", nil, errors.New("transaction empty")
+	}
+	if lib.IsEmpty(proposalStr) {
+		return rawTx, nil, nil
+	}
+
+	proposal := &ProposedTx{}
+	if err := json.Unmarshal([]byte(proposalStr), proposal); err != nil {
+		return "", nil, err
+	}
+	return "", proposal, nil
+}
+
+// simulateInput evaluates the program of input at position, the arguments of
+// input are used if arguments is nil
+func simulateInput(dialect, rawTx string, proposal *ProposedTx, position uint32, arguments [][]byte, blockHeight uint64, gasLimit int64) (*RespSimulateInput, error) {
+	var (
+		txID    string
+		gasLeft int64
+	)
+
+	if dialect == vmVapor {
+		txData, err := vaporTxData(rawTx, proposal)
+		if err != nil {
+			return nil, err
+		}
+
+		if int(position) >= len(txData.Inputs) {
+			return nil, errors.WithDetailf(vaporvalidation.ErrBadInputIdx, "position %d, %d inputs", position, len(txData.Inputs))
+		}
+		if arguments == nil {
+			arguments = txData.Inputs[position].Arguments()
+		}
+		txID = vaportypes.MapTx(txData).ID.String()
+		gasLeft, err = vaporvalidation.SimulateInput(txData, position, arguments, blockHeight, gasLimit)
+		return newRespSimulateInput(txID, gasLeft, err), nil
+	}
+
+	txData, err := bytomTxData(rawTx, proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	if int(position) >= len(txData.Inputs) {
+		return nil, errors.WithDetailf(validation.ErrBadInputIdx, "position %d, %d inputs", position, len(txData.Inputs))
+	}
+	if arguments == nil {
+		arguments = txData.Inputs[position].Arguments()
+	}
+	txID = types.MapTx(txData).ID.String()
+	gasLeft, err = validation.SimulateInput(txData, position, arguments, blockHeight, gasLimit)
+	return newRespSimulateInput(txID, gasLeft, err), nil
+}
+
+func newRespSimulateInput(txID string, gasLeft int64, err error) *RespSimulateInput {
+	resp := &RespSimulateInput{TxID: txID, Valid: err == nil, GasLeft: gasLeft}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+// SimulateInput evaluate the program of input of the proposed transaction with the chosen arguments
+func SimulateInput(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	rawTx, proposal, err := parseTxArgs(args[0])
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	dialect, err := checkVMDialect(args[0].Get("vm").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	var arguments [][]byte
+	if argumentsStr := args[0].Get("arguments").String(); !lib.IsEmpty(argumentsStr) {
+		var hexArgs []chainjson.HexBytes
+		if err := json.Unmarshal([]byte(argumentsStr), &hexArgs); err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+		arguments = make([][]byte, 0, len(hexArgs))
+		for _, arg := range hexArgs {
+			arguments = append(arguments, arg)
+		}
+	}
+
+	var position uint32
+	if p := args[0].Get("position"); p.Type() == js.TypeNumber {
+		position = uint32(p.Int())
+	}
+	var blockHeight uint64
+	if h := args[0].Get("block_height"); h.Type() == js.TypeNumber {
+		blockHeight = uint64(h.Int())
+	}
+	gasLimit := defaultGasLimit
+	if g := args[0].Get("gas_limit"); g.Type() == js.TypeNumber {
+		gasLimit = int64(g.Int())
+	}
+
+	resp, err := simulateInput(dialect, rawTx, proposal, position, arguments, blockHeight, gasLimit)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
@@ -0,0 +1,248 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/pseudohsm"
+	"github.com/bytom-community/wasm/bytom/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/sdk/lib"
+)
+
+// RespSignTemplate is the response of SignTemplate
+type RespSignTemplate struct {
+	Template        *txbuilder.Template            `json:"transaction"`
+	SignComplete    bool                           `json:"sign_complete"`
+	SigningProgress []*txbuilder.InputSignProgress `json:"signing_progress"`
+}
+
+// keySignFunc return the txbuilder.SignFunc backed by the decrypted key
+func keySignFunc(key *pseudohsm.XKey) txbuilder.SignFunc {
+	return keysSignFunc(map[chainkd.XPub]*pseudohsm.XKey{key.XPub: key})
+}
+
+// keysSignFunc return the txbuilder.SignFunc backed by the decrypted keys, it
+// only reads the keys so it is safe for concurrent use
+func keysSignFunc(keys map[chainkd.XPub]*pseudohsm.XKey) txbuilder.SignFunc {
+	return func(_ context.Context, xpub chainkd.XPub, path [][]byte, data [32]byte, _ string) ([]byte, error) {
+		key, ok := keys[xpub]
+		if !ok {
+			return nil, errors.Wrap(txbuilder.ErrKeyNotOwned)
+		}
+		return signWithKey(key, path, data[:]), nil
+	}
+}
+
+// checkTemplateTxID check the transaction of template is the one of the summary confirmed
+// by the user, the sighash of each input is always recomputed from the transaction by
+// txbuilder.Sign, so the tx_id binds the signatures to the confirmed transaction
+func checkTemplateTxID(txID string, tpl *txbuilder.Template) error {
+	if lib.IsEmpty(txID) {
+		return nil
+	}
+	if tpl.Transaction == nil {
+		return errors.Wrap(txbuilder.ErrMissingRawTx)
+	}
+	return checkTxID(txID, tpl.Transaction.ID)
+}
+
+// SignTemplate sign the transaction template and materialize the witnesses, the transaction
+// of other id than the tx_id of the confirmed summary is refused if the tx_id is given
+func SignTemplate(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	transaction := args[0].Get("transaction").String()
+	password := args[0].Get("password").String()
+	keyJSON := args[0].Get("key").String()
+	if lib.IsEmpty(transaction) || lib.IsEmpty(password) || lib.IsEmpty(keyJSON) {
+		args[1].Set("error", "args empty")
+		return nil
+	}
+
+	tpl := &txbuilder.Template{}
+	if err := json.Unmarshal([]byte(transaction), tpl); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if err := checkTemplateTxID(args[0].Get("tx_id").String(), tpl); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	if err := checkWatchOnly(args[0].Get("account").String(), keyJSON); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	key, err := pseudohsm.DecryptKey([]byte(keyJSON), password)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	if err := txbuilder.Sign(context.Background(), tpl, password, keySignFunc(key)); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(newRespSignTemplate(tpl))
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// SignPartialTemplate sign the inputs of transaction template under the allow additional actions
+// mode, the signature programs commit to the custom constraints so that the counterparty can
+// add their inputs and outputs to the signed partial template.
+// The inputs of standard accounts are signed by raw transaction signature and can't be
+// partially signed, the template including them is refused. The tx_id is checked in the
+// same way as SignTemplate.
+func SignPartialTemplate(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	transaction := args[0].Get("transaction").String()
+	password := args[0].Get("password").String()
+	keyJSON := args[0].Get("key").String()
+	constraintsJSON := args[0].Get("constraints").String()
+	if lib.IsEmpty(transaction) || lib.IsEmpty(password) || lib.IsEmpty(keyJSON) {
+		args[1].Set("error", "args empty")
+		return nil
+	}
+
+	tpl := &txbuilder.Template{}
+	if err := json.Unmarshal([]byte(transaction), tpl); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if err := checkTemplateTxID(args[0].Get("tx_id").String(), tpl); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	var constraints txbuilder.InputSigConstraints
+	if !lib.IsEmpty(constraintsJSON) {
+		if err := json.Unmarshal([]byte(constraintsJSON), &constraints); err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+	}
+
+	// the standard account inputs are refused before the key is decrypted
+	tpl.AllowAdditional = true
+	if err := txbuilder.ApplySigConstraints(tpl, constraints); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	if err := checkWatchOnly(args[0].Get("account").String(), keyJSON); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	key, err := pseudohsm.DecryptKey([]byte(keyJSON), password)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	if err := txbuilder.SignWithConstraints(context.Background(), tpl, constraints, password, keySignFunc(key)); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(newRespSignTemplate(tpl))
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// MergeTemplates merge the signatures of the partially signed templates from different cosigners
+func MergeTemplates(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	templates := args[0].Get("templates").String()
+	if lib.IsEmpty(templates) {
+		args[1].Set("error", "templates empty")
+		return nil
+	}
+
+	var tpls []*txbuilder.Template
+	if err := json.Unmarshal([]byte(templates), &tpls); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if len(tpls) == 0 {
+		args[1].Set("error", "templates empty")
+		return nil
+	}
+
+	for i := 1; i < len(tpls); i++ {
+		if err := txbuilder.MergeTemplates(tpls[0], tpls[i]); err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+	}
+
+	j, err := json.Marshal(newRespSignTemplate(tpls[0]))
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+func newRespSignTemplate(tpl *txbuilder.Template) *RespSignTemplate {
+	return &RespSignTemplate{
+		Template:        tpl,
+		SignComplete:    txbuilder.SignProgress(tpl),
+		SigningProgress: txbuilder.SignProgressDetail(tpl),
+	}
+}
+
+// RespVerifyTemplate is the response of VerifyTransactionSignatures
+type RespVerifyTemplate struct {
+	Signatures []*txbuilder.SignatureStatus `json:"signatures"`
+}
+
+// VerifyTransactionSignatures verify the signatures of template and report which keys have validly signed which inputs
+func VerifyTransactionSignatures(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	transaction := args[0].Get("transaction").String()
+	if lib.IsEmpty(transaction) {
+		args[1].Set("error", "transaction empty")
+		return nil
+	}
+
+	tpl := &txbuilder.Template{}
+	if err := json.Unmarshal([]byte(transaction), tpl); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	var constraints txbuilder.InputSigConstraints
+	if constraintsJSON := args[0].Get("constraints").String(); !lib.IsEmpty(constraintsJSON) {
+		if err := json.Unmarshal([]byte(constraintsJSON), &constraints); err != nil {
+			args[1].Set("error", err.Error())
+			return nil
+		}
+	}
+
+	statuses, err := txbuilder.VerifySignatures(tpl, constraints)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(RespVerifyTemplate{Signatures: statuses})
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
@@ -0,0 +1,105 @@
+package base
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+)
+
+// newTestTemplate returns the template json of the test transaction signed by the
+// xpub, the inputs are signed by raw transaction signature unless sigProgram
+func newTestTemplate(t *testing.T, xpub chainkd.XPub, sigProgram bool) (string, bc.Hash) {
+	tx := newTestTx()
+	tpl := &txbuilder.Template{Transaction: tx}
+	for i := range tx.Inputs {
+		sigInst := &txbuilder.SigningInstruction{Position: uint32(i)}
+		if sigProgram {
+			sigInst.AddWitnessKeys([]chainkd.XPub{xpub}, [][]byte{{1}}, 1)
+		} else {
+			sigInst.AddRawWitnessKeys([]chainkd.XPub{xpub}, [][]byte{{1}}, 1)
+		}
+		tpl.SigningInstructions = append(tpl.SigningInstructions, sigInst)
+	}
+
+	b, err := json.Marshal(tpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b), tx.ID
+}
+
+func TestSignTemplatesTxID(t *testing.T) {
+	key, keyJSON := newTestKey(t)
+	transaction, txID := newTestTemplate(t, key.XPub, false)
+	partialTransaction, partialTxID := newTestTemplate(t, key.XPub, true)
+	otherTxID := bc.Hash{V0: 1}
+
+	type signFunc func(string, string) (string, string)
+	signTemplate := func(transaction, txID string) (string, string) {
+		return callSDK(SignTemplate, map[string]interface{}{"transaction": transaction, "password": testPassword, "key": keyJSON, "tx_id": txID})
+	}
+	signPartialTemplate := func(transaction, txID string) (string, string) {
+		return callSDK(SignPartialTemplate, map[string]interface{}{"transaction": transaction, "password": testPassword, "key": keyJSON, "tx_id": txID})
+	}
+	signTemplateWithSigners := func(transaction, txID string) (string, string) {
+		return callSDK(SignTemplateWithSigners, map[string]interface{}{"transaction": transaction, "password": testPassword, "keys": "[" + keyJSON + "]", "tx_id": txID})
+	}
+	signTemplates := func(transaction, txID string) (string, string) {
+		txIDs := ""
+		if txID != "" {
+			txIDs = `["` + txID + `"]`
+		}
+		return callSDK(SignTemplates, map[string]interface{}{"templates": "[" + transaction + "]", "password": testPassword, "keys": "[" + keyJSON + "]", "tx_ids": txIDs})
+	}
+
+	cases := []struct {
+		desc        string
+		sign        signFunc
+		transaction string
+		txID        bc.Hash
+	}{
+		{desc: "signTemplate", sign: signTemplate, transaction: transaction, txID: txID},
+		{desc: "signPartialTemplate", sign: signPartialTemplate, transaction: partialTransaction, txID: partialTxID},
+		{desc: "signTemplateWithSigners", sign: signTemplateWithSigners, transaction: transaction, txID: txID},
+		{desc: "signTemplates", sign: signTemplates, transaction: transaction, txID: txID},
+	}
+
+	for _, c := range cases {
+		if _, errMsg := c.sign(c.transaction, ""); errMsg != "" {
+			t.Errorf("%s without tx_id: %s", c.desc, errMsg)
+		}
+		if _, errMsg := c.sign(c.transaction, c.txID.String()); errMsg != "" {
+			t.Errorf("%s with confirmed tx_id: %s", c.desc, errMsg)
+		}
+		if _, errMsg := c.sign(c.transaction, otherTxID.String()); !strings.Contains(errMsg, errTxIDMismatch.Error()) {
+			t.Errorf("%s with other tx_id: got error %q, want %q", c.desc, errMsg, errTxIDMismatch.Error())
+		}
+	}
+}
+
+func TestSignTemplatesTxIDCount(t *testing.T) {
+	key, keyJSON := newTestKey(t)
+	transaction, txID := newTestTemplate(t, key.XPub, false)
+	_, errMsg := callSDK(SignTemplates, map[string]interface{}{
+		"templates": "[" + transaction + "," + transaction + "]",
+		"password":  testPassword,
+		"keys":      "[" + keyJSON + "]",
+		"tx_ids":    `["` + txID.String() + `"]`,
+	})
+	if !strings.Contains(errMsg, errTxIDMismatch.Error()) {
+		t.Errorf("got error %q, want %q", errMsg, errTxIDMismatch.Error())
+	}
+}
+
+func TestSignPartialTemplateStandardInput(t *testing.T) {
+	key, keyJSON := newTestKey(t)
+	transaction, _ := newTestTemplate(t, key.XPub, false)
+	_, errMsg := callSDK(SignPartialTemplate, map[string]interface{}{"transaction": transaction, "password": testPassword, "key": keyJSON})
+	if want := txbuilder.ErrRawTxSigAllowAdditional.Error(); !strings.Contains(errMsg, want) {
+		t.Errorf("got error %q, want %q", errMsg, want)
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"syscall/js"
 
+	"github.com/bytom-community/wasm/bytom/blockchain"
 	"github.com/bytom-community/wasm/bytom/blockchain/pseudohsm"
 	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
 	"github.com/bytom-community/wasm/sdk/lib"
 )
 
@@ -16,6 +20,7 @@ const getKeyByXPub = "getKeyByXPub"
 type Template struct {
 	Transaction         string `json:"raw_transaction"`
 	SigningInstructions []struct {
+		Position       uint32               `json:"position"`
 		DerivationPath []chainjson.HexBytes `json:"derivation_path"`
 		SignData       []string             `json:"sign_data"`
 	} `json:"signing_instructions"`
@@ -27,7 +32,107 @@ type RespSign struct {
 	Signatures  [][]string `json:"signatures"`
 }
 
-// SignTransaction sign transaction
+var (
+	// errSignDataMismatch means the sign data is not the sighash of raw transaction
+	errSignDataMismatch = errors.New("sign data mismatches the raw transaction")
+	// errTxIDMismatch means the raw transaction is not the one of the confirmed summary
+	errTxIDMismatch = errors.New("tx_id mismatches the raw transaction")
+)
+
+// parseSignTemplate parse the template of signTransaction, and check the sign data
+// is the sighash of the input at position, so that the caller can't make the key
+// sign an unrelated transaction
+func parseSignTemplate(transaction string) (*Template, *types.Tx, error) {
+	var tpl Template
+	if err := json.Unmarshal([]byte(transaction), &tpl); err != nil {
+		return nil, nil, err
+	}
+
+	rawTx := &types.Tx{}
+	if err := rawTx.UnmarshalText([]byte(tpl.Transaction)); err != nil {
+		return nil, nil, err
+	}
+	for _, v := range tpl.SigningInstructions {
+		if err := checkSignData(rawTx, v.Position, v.SignData); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &tpl, rawTx, nil
+}
+
+// checkSignData check the sign data is the sighash of the input at position, the
+// hex sign data is decoded before comparing so that its case doesn't matter
+func checkSignData(tx *types.Tx, position uint32, signData []string) error {
+	if position >= uint32(len(tx.Inputs)) {
+		return errors.WithDetailf(errSignDataMismatch, "missing tx input %d", position)
+	}
+
+	sigHash := tx.SigHash(position)
+	for _, d := range signData {
+		var h bc.Hash
+		if err := h.UnmarshalText([]byte(d)); err != nil {
+			return errors.WithDetailf(errSignDataMismatch, "bad sign data %s of input %d", d, position)
+		}
+		if h != sigHash {
+			return errors.WithDetailf(errSignDataMismatch, "sign data %s of input %d", d, position)
+		}
+	}
+	return nil
+}
+
+// checkTxID check the transaction is the one of the summary confirmed by the user, the
+// tx_id is opt-in so nothing is checked if it is omitted
+func checkTxID(txID string, id bc.Hash) error {
+	if lib.IsEmpty(txID) {
+		return nil
+	}
+
+	var confirmedID bc.Hash
+	if err := confirmedID.UnmarshalText([]byte(txID)); err != nil || confirmedID != id {
+		return errors.WithDetailf(errTxIDMismatch, "tx_id %s", txID)
+	}
+	return nil
+}
+
+// SummarizeTransaction return the summary of transaction to be signed by signTransaction or
+// the template signing functions, the host should show it to the user for confirmation, and
+// pass its tx_id to the signing function
+func SummarizeTransaction(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	transaction := args[0].Get("transaction").String()
+	if lib.IsEmpty(transaction) {
+		args[1].Set("error", "transaction empty")
+		return nil
+	}
+
+	_, rawTx, err := parseSignTemplate(transaction)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	netParams, err := getNetParams(args[0].Get("network").String())
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	summary, err := blockchain.BuildTxSummary(rawTx, netParams)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(summary)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
+
+// SignTransaction sign transaction, the transaction of other id than the tx_id of the
+// confirmed summary is refused if the tx_id is given
 func SignTransaction(this js.Value, args []js.Value) interface{} {
 	defer lib.EndFunc(args[1])
 	transaction := args[0].Get("transaction").String()
@@ -37,12 +142,23 @@ func SignTransaction(this js.Value, args []js.Value) interface{} {
 		args[1].Set("error", "args empty")
 		return nil
 	}
-	var tx Template
-	err := json.Unmarshal([]byte(transaction), &tx)
+
+	if err := checkWatchOnly(args[0].Get("account").String(), keyJSON); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	tx, rawTx, err := parseSignTemplate(transaction)
 	if err != nil {
 		args[1].Set("error", err.Error())
 		return nil
 	}
+
+	if err := checkTxID(args[0].Get("tx_id").String(), rawTx.ID); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
 	signRet := make([][]string, len(tx.SigningInstructions))
 	for k, v := range tx.SigningInstructions {
 		path := make([][]byte, len(v.DerivationPath))
@@ -50,14 +166,12 @@ func SignTransaction(this js.Value, args []js.Value) interface{} {
 			path[i] = p
 		}
 		for _, d := range v.SignData {
-			var h [32]byte
-			t, err := hex.DecodeString(d)
-			if err != nil {
+			var h bc.Hash
+			if err := h.UnmarshalText([]byte(d)); err != nil {
 				args[1].Set("error", err.Error())
 				return nil
 			}
-			copy(h[:], t)
-			signData, err := SignData(keyJSON, path, h[:], password)
+			signData, err := SignData(keyJSON, path, h.Bytes(), password)
 			if err != nil {
 				args[1].Set("error", err.Error())
 				return nil
@@ -91,10 +205,14 @@ func SignData(keyJSON string, path [][]byte, data []byte, password string) ([]by
 	if err != nil {
 		return nil, err
 	}
+	return signWithKey(key, path, data), nil
+}
 
+// signWithKey sign the data by the decrypted key derived with the path
+func signWithKey(key *pseudohsm.XKey, path [][]byte, data []byte) []byte {
 	xprv := key.XPrv
 	if len(path) > 0 {
 		xprv = key.XPrv.Derive(path)
 	}
-	return xprv.Sign(data[:]), nil
+	return xprv.Sign(data[:])
 }
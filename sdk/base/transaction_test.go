@@ -0,0 +1,138 @@
+package base
+
+import (
+	"encoding/json"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/pseudohsm"
+	"github.com/bytom-community/wasm/bytom/consensus"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/protocol/bc"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/pborman/uuid"
+)
+
+const testPassword = "password"
+
+// optionalParams are given as empty strings if omitted, js.Value.String of the
+// undefined value is not "undefined" since Go 1.14
+var optionalParams = []string{"account", "tx_id", "tx_ids", "constraints", "network", "keys", "password"}
+
+func newTestKey(t *testing.T) (*pseudohsm.XKey, string) {
+	xprv, xpub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := &pseudohsm.XKey{ID: uuid.NewRandom(), KeyType: "bytom_kd", Alias: "test", XPrv: xprv, XPub: xpub}
+	keyJSON, err := pseudohsm.EncryptKey(key, testPassword, pseudohsm.LightScryptN, pseudohsm.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(keyJSON)
+}
+
+func newTestTx() *types.Tx {
+	return types.NewTx(types.TxData{
+		Version: 1,
+		Inputs: []*types.TxInput{
+			types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 10000, 0, []byte{byte(vm.OP_TRUE)}),
+			types.NewSpendInput(nil, bc.Hash{V0: 2}, *consensus.BTMAssetID, 20000, 0, []byte{byte(vm.OP_TRUE)}),
+		},
+		Outputs: []*types.TxOutput{types.NewTxOutput(*consensus.BTMAssetID, 25000, []byte{byte(vm.OP_TRUE)})},
+	})
+}
+
+// callSDK calls the sdk function with the params and waits for the end of the
+// call, then returns the data or the error of the result
+func callSDK(fn func(js.Value, []js.Value) interface{}, params map[string]interface{}) (string, string) {
+	for _, name := range optionalParams {
+		if _, ok := params[name]; !ok {
+			params[name] = ""
+		}
+	}
+
+	done := make(chan struct{}, 1)
+	endFunc := js.FuncOf(func(js.Value, []js.Value) interface{} {
+		done <- struct{}{}
+		return nil
+	})
+	defer endFunc.Release()
+
+	result := js.Global().Get("Object").New()
+	result.Set("endFunc", endFunc)
+	fn(js.Undefined(), []js.Value{js.ValueOf(params), result})
+	<-done
+	if e := result.Get("error"); e.Type() == js.TypeString {
+		return "", e.String()
+	}
+	return result.Get("data").String(), ""
+}
+
+func TestSignTransaction(t *testing.T) {
+	_, keyJSON := newTestKey(t)
+	tx := newTestTx()
+	rawTx, err := tx.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigHash, otherSigHash := tx.SigHash(0), tx.SigHash(1)
+	otherTx := newTestTx()
+	otherTx.Outputs[0] = types.NewTxOutput(*consensus.BTMAssetID, 20000, []byte{byte(vm.OP_TRUE)})
+	otherTx = types.NewTx(otherTx.TxData)
+
+	cases := []struct {
+		desc     string
+		signData string
+		txID     string
+		wantErr  string
+	}{
+		{desc: "without tx_id", signData: sigHash.String()},
+		{desc: "confirmed tx_id", signData: sigHash.String(), txID: tx.ID.String()},
+		{desc: "other tx_id", signData: sigHash.String(), txID: otherTx.ID.String(), wantErr: errTxIDMismatch.Error()},
+		{desc: "bad tx_id", signData: sigHash.String(), txID: "00", wantErr: errTxIDMismatch.Error()},
+		{desc: "sign data of other input", signData: otherSigHash.String(), wantErr: errSignDataMismatch.Error()},
+	}
+
+	for _, c := range cases {
+		transaction := map[string]interface{}{
+			"raw_transaction": string(rawTx),
+			"signing_instructions": []interface{}{
+				map[string]interface{}{"position": 0, "derivation_path": []interface{}{}, "sign_data": []interface{}{c.signData}},
+			},
+		}
+		b, err := json.Marshal(transaction)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, errMsg := callSDK(SignTransaction, map[string]interface{}{
+			"transaction": string(b),
+			"password":    testPassword,
+			"key":         keyJSON,
+			"tx_id":       c.txID,
+		})
+		if c.wantErr != "" {
+			if !strings.Contains(errMsg, c.wantErr) {
+				t.Errorf("%s: got error %q, want %q", c.desc, errMsg, c.wantErr)
+			}
+			continue
+		}
+		if errMsg != "" {
+			t.Errorf("%s: %s", c.desc, errMsg)
+			continue
+		}
+
+		var resp RespSign
+		if err := json.Unmarshal([]byte(data), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Signatures) != 1 || len(resp.Signatures[0]) != 1 {
+			t.Errorf("%s: got signatures %v", c.desc, resp.Signatures)
+		}
+	}
+}
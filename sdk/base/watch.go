@@ -0,0 +1,160 @@
+package base
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/account"
+	"github.com/bytom-community/wasm/bytom/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/bc/types"
+	"github.com/bytom-community/wasm/sdk/lib"
+)
+
+// TemplateInput is the account input of unsigned template
+type TemplateInput struct {
+	Position uint32 `json:"position"`
+	KeyIndex uint64 `json:"key_index"`
+}
+
+// ExportWatchAccount export account to watch-only account
+func ExportWatchAccount(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1]) //end func call
+	var acc account.Account
+	if err := json.Unmarshal([]byte(args[0].Get("account").String()), &acc); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if acc.Signer == nil {
+		args[1].Set("error", "account signer empty")
+		return nil
+	}
+
+	// the root xpubs derive all the accounts under them, they are only
+	// exported on request
+	var withRootXPubs bool
+	if r := args[0].Get("root_xpubs"); r.Type() == js.TypeBoolean {
+		withRootXPubs = r.Bool()
+	}
+
+	watchAccount, err := account.ExportWatchOnly(&acc, withRootXPubs)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	rawAccount, err := json.Marshal(watchAccount)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(rawAccount))
+	return nil
+}
+
+// ImportWatchAccount import the exported watch-only account
+func ImportWatchAccount(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1]) //end func call
+	var acc account.Account
+	if err := json.Unmarshal([]byte(args[0].Get("account").String()), &acc); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	if err := account.ImportWatchOnly(&acc); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	rawAccount, err := json.Marshal(acc)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(rawAccount))
+	return nil
+}
+
+// CreateUnsignedTemplate create the unsigned template for the account inputs of transaction
+func CreateUnsignedTemplate(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1]) //end func call
+	rawTx := args[0].Get("raw_transaction").String()
+	if lib.IsEmpty(rawTx) {
+		args[1].Set("error", "raw_transaction empty")
+		return nil
+	}
+
+	var acc account.Account
+	if err := json.Unmarshal([]byte(args[0].Get("account").String()), &acc); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if acc.Signer == nil {
+		args[1].Set("error", "account signer empty")
+		return nil
+	}
+
+	var inputs []TemplateInput
+	if err := json.Unmarshal([]byte(args[0].Get("inputs").String()), &inputs); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	tpl, err := createUnsignedTemplate(rawTx, &acc, inputs)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	data, err := json.Marshal(tpl)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(data))
+	return nil
+}
+
+func createUnsignedTemplate(rawTx string, acc *account.Account, inputs []TemplateInput) (*txbuilder.Template, error) {
+	tx := &types.Tx{}
+	if err := tx.UnmarshalText([]byte(rawTx)); err != nil {
+		return nil, err
+	}
+
+	tpl := &txbuilder.Template{Transaction: tx}
+	for _, input := range inputs {
+		if input.Position >= uint32(len(tx.Inputs)) {
+			return nil, errors.WithDetailf(txbuilder.ErrBadTxInputIdx, "input position %d", input.Position)
+		}
+
+		sigInst, err := account.SigningInstruction(acc, input.Position, input.KeyIndex)
+		if err != nil {
+			return nil, err
+		}
+		tpl.SigningInstructions = append(tpl.SigningInstructions, sigInst)
+	}
+	return tpl, nil
+}
+
+// checkWatchOnly refuses the watch-only account to sign, the account is
+// optional, the key must be one of its root keys if it is given. Nothing is
+// checked without the account, so the caller must pass it to be protected.
+func checkWatchOnly(accountJSON, keyJSON string) error {
+	if lib.IsEmpty(accountJSON) {
+		return nil
+	}
+
+	var acc account.Account
+	if err := json.Unmarshal([]byte(accountJSON), &acc); err != nil {
+		return err
+	}
+
+	var key struct {
+		XPub chainkd.XPub `json:"xpub"`
+	}
+	if err := json.Unmarshal([]byte(keyJSON), &key); err != nil {
+		return err
+	}
+	return account.CheckSigningKey(&acc, key.XPub)
+}
@@ -0,0 +1,333 @@
+// Package classify tells the type of the control programs of bytom and
+// vapor, and parses the fields of the standard programs.
+package classify
+
+import (
+	"bytes"
+
+	"github.com/bytom-community/wasm/bytom/consensus/segwit"
+	chainjson "github.com/bytom-community/wasm/bytom/encoding/json"
+	"github.com/bytom-community/wasm/bytom/errors"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+	"github.com/bytom-community/wasm/equity/templates"
+	vaporsegwit "github.com/bytom-community/wasm/vapor/consensus/segwit"
+	vaporvm "github.com/bytom-community/wasm/vapor/protocol/vm"
+	vaporvmutil "github.com/bytom-community/wasm/vapor/protocol/vm/vmutil"
+)
+
+// the chains of control program
+const (
+	ChainBytom = "bytom"
+	ChainVapor = "vapor"
+)
+
+// the types of control program
+const (
+	TypeP2WPKH      = "P2WPKH"
+	TypeP2WSH       = "P2WSH"
+	TypeP2PKH       = "P2PKH"
+	TypeP2SH        = "P2SH"
+	TypeMultiSig    = "P2SPMultiSig"
+	TypeRetire      = "Retire"
+	TypeCoinbase    = "Coinbase"
+	TypeP2WMC       = "P2WMC"
+	TypeP2MC        = "P2MC"
+	TypeVote        = "Vote"
+	TypeTemplate    = "Template"
+	TypeNonStandard = "NonStandard"
+)
+
+// ErrBadChain is returned when the chain is neither bytom nor vapor
+var ErrBadChain = errors.New("bad chain")
+
+// Program is the classified control program, only the fields of its type
+// are set
+type Program struct {
+	Type  string `json:"type"`
+	Chain string `json:"vm"`
+
+	// WitnessVersion is the version of segwit program
+	WitnessVersion *int64 `json:"witness_version,omitempty"`
+
+	// PubkeyHash is the hash of P2WPKH and P2PKH program
+	PubkeyHash chainjson.HexBytes `json:"pubkey_hash,omitempty"`
+
+	// ScriptHash is the hash of P2WSH and P2SH program
+	ScriptHash chainjson.HexBytes `json:"script_hash,omitempty"`
+
+	// Pubkeys and Quorum are the public keys and the number of required
+	// signatures of multisig program, BlockHeight is the height after
+	// which the vapor multisig program can be spent
+	Pubkeys     []chainjson.HexBytes `json:"pubkeys,omitempty"`
+	Quorum      int                  `json:"quorum,omitempty"`
+	BlockHeight int64                `json:"block_height,omitempty"`
+
+	// Comment is the data following the FAIL of retire program
+	Comment chainjson.HexBytes `json:"comment,omitempty"`
+
+	// MagneticContract is the arguments of vapor magnetic contract
+	MagneticContract *MagneticContract `json:"magnetic_contract,omitempty"`
+
+	// Template and TemplateArguments are the name and the contract
+	// arguments of the standard contract template
+	Template          string               `json:"template,omitempty"`
+	TemplateArguments []chainjson.HexBytes `json:"template_arguments,omitempty"`
+
+	// Vote is the public key voted by vapor vote output, whose owner
+	// program is Owner
+	Vote  chainjson.HexBytes `json:"vote,omitempty"`
+	Owner *Program           `json:"owner,omitempty"`
+}
+
+// MagneticContract is the arguments of vapor magnetic contract
+type MagneticContract struct {
+	RequestedAsset   chainjson.HexBytes `json:"requested_asset"`
+	RatioNumerator   int64              `json:"ratio_numerator"`
+	RatioDenominator int64              `json:"ratio_denominator"`
+	SellerProgram    chainjson.HexBytes `json:"seller_program"`
+	SellerKey        chainjson.HexBytes `json:"seller_key"`
+}
+
+// Classify returns the type and the parsed fields of the control program
+// on the chain
+func Classify(chain string, program []byte) (*Program, error) {
+	switch chain {
+	case ChainBytom:
+		return classifyBytom(program), nil
+	case ChainVapor:
+		return classifyVapor(program), nil
+	default:
+		return nil, errors.WithDetail(ErrBadChain, chain)
+	}
+}
+
+// ClassifyVote returns the vote output of vapor, whose owner is the
+// classified control program
+func ClassifyVote(program, vote []byte) (*Program, error) {
+	owner, err := Classify(ChainVapor, program)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{Type: TypeVote, Chain: ChainVapor, Vote: vote, Owner: owner}, nil
+}
+
+func classifyBytom(program []byte) *Program {
+	p := &Program{Type: TypeNonStandard, Chain: ChainBytom}
+	insts, err := vm.ParseProgram(program)
+	if err != nil {
+		return p
+	}
+
+	switch {
+	case segwit.IsP2WPKHScript(program):
+		p.Type, p.PubkeyHash = TypeP2WPKH, insts[1].Data
+		p.WitnessVersion = witnessVersion(insts[0].Data)
+
+	case segwit.IsP2WSHScript(program):
+		p.Type, p.ScriptHash = TypeP2WSH, insts[1].Data
+		p.WitnessVersion = witnessVersion(insts[0].Data)
+
+	case vmutil.IsUnspendable(program):
+		p.Type, p.Comment = TypeRetire, retireComment(len(insts), insts[len(insts)-1].Data)
+
+	case len(insts) == 1 && insts[0].Op == vm.OP_TRUE:
+		p.Type = TypeCoinbase
+
+	case len(insts) > 2 && insts[2].IsPushdata() && isProgram(program, vmutil.P2PKHSigProgram, insts[2].Data):
+		p.Type, p.PubkeyHash = TypeP2PKH, insts[2].Data
+
+	case len(insts) > 2 && insts[2].IsPushdata() && isProgram(program, vmutil.P2SHProgram, insts[2].Data):
+		p.Type, p.ScriptHash = TypeP2SH, insts[2].Data
+
+	default:
+		if !classifyBytomMultiSig(p, program) {
+			classifyTemplate(p, program)
+		}
+	}
+	return p
+}
+
+// classifyBytomMultiSig classifies the program generated by
+// P2SPMultiSigProgram
+//
+//	TXSIGHASH <pubkey>... <quorum> <npubkeys> CHECKMULTISIG
+func classifyBytomMultiSig(p *Program, program []byte) bool {
+	pubkeys, quorum, err := vmutil.DecodeP2SPMultiSigProgram(program)
+	if err != nil {
+		return false
+	}
+
+	// the program must be what P2SPMultiSigProgram generates
+	rebuilt, err := vmutil.P2SPMultiSigProgram(pubkeys, quorum)
+	if err != nil || !bytes.Equal(rebuilt, program) {
+		return false
+	}
+
+	p.Type, p.Quorum = TypeMultiSig, quorum
+	for _, pubkey := range pubkeys {
+		p.Pubkeys = append(p.Pubkeys, chainjson.HexBytes(pubkey))
+	}
+	return true
+}
+
+func classifyVapor(program []byte) *Program {
+	p := &Program{Type: TypeNonStandard, Chain: ChainVapor}
+	insts, err := vaporvm.ParseProgram(program)
+	if err != nil {
+		return p
+	}
+
+	switch {
+	case vaporsegwit.IsP2WPKHScript(program):
+		p.Type, p.PubkeyHash = TypeP2WPKH, insts[1].Data
+		p.WitnessVersion = witnessVersion(insts[0].Data)
+
+	case vaporsegwit.IsP2WSHScript(program):
+		p.Type, p.ScriptHash = TypeP2WSH, insts[1].Data
+		p.WitnessVersion = witnessVersion(insts[0].Data)
+
+	case vaporsegwit.IsP2WMCScript(program):
+		args, err := vaporsegwit.DecodeP2WMCProgram(program)
+		if err != nil {
+			return p
+		}
+		p.Type, p.MagneticContract = TypeP2WMC, magneticContract(args)
+		p.WitnessVersion = witnessVersion(insts[0].Data)
+
+	case vaporvmutil.IsUnspendable(program):
+		p.Type, p.Comment = TypeRetire, retireComment(len(insts), insts[len(insts)-1].Data)
+
+	case len(insts) == 1 && insts[0].Op == vaporvm.OP_TRUE:
+		p.Type = TypeCoinbase
+
+	case len(insts) > 2 && insts[2].IsPushdata() && isProgram(program, vaporvmutil.P2PKHSigProgram, insts[2].Data):
+		p.Type, p.PubkeyHash = TypeP2PKH, insts[2].Data
+
+	case len(insts) > 2 && insts[2].IsPushdata() && isProgram(program, vaporvmutil.P2SHProgram, insts[2].Data):
+		p.Type, p.ScriptHash = TypeP2SH, insts[2].Data
+
+	default:
+		if args := decodeP2MCProgram(program, insts); args != nil {
+			p.Type, p.MagneticContract = TypeP2MC, magneticContract(args)
+		} else if !classifyVaporMultiSig(p, program, insts) {
+			classifyTemplate(p, program)
+		}
+	}
+	return p
+}
+
+// classifyVaporMultiSig classifies the program generated by
+// P2SPMultiSigProgramWithHeight, which is P2SPMultiSigProgram if the block
+// height is 0
+//
+//	<height> BLOCKHEIGHT GREATERTHAN VERIFY <multisig program>
+func classifyVaporMultiSig(p *Program, program []byte, insts []vaporvm.Instruction) bool {
+	var blockHeight int64
+	multisig := program
+	if len(insts) > 4 && insts[1].Op == vaporvm.OP_BLOCKHEIGHT && insts[2].Op == vaporvm.OP_GREATERTHAN && insts[3].Op == vaporvm.OP_VERIFY {
+		height, err := vaporvm.AsInt64(insts[0].Data)
+		if err != nil || height <= 0 {
+			return false
+		}
+
+		prefix := uint32(0)
+		for _, inst := range insts[:4] {
+			prefix += inst.Len
+		}
+		blockHeight, multisig = height, program[prefix:]
+	}
+
+	pubkeys, quorum, err := vaporvmutil.DecodeP2SPMultiSigProgram(multisig)
+	if err != nil {
+		return false
+	}
+
+	// the program must be what P2SPMultiSigProgramWithHeight generates
+	rebuilt, err := vaporvmutil.P2SPMultiSigProgramWithHeight(pubkeys, quorum, blockHeight)
+	if err != nil || !bytes.Equal(rebuilt, program) {
+		return false
+	}
+
+	p.Type, p.Quorum, p.BlockHeight = TypeMultiSig, quorum, blockHeight
+	for _, pubkey := range pubkeys {
+		p.Pubkeys = append(p.Pubkeys, chainjson.HexBytes(pubkey))
+	}
+	return true
+}
+
+// decodeP2MCProgram returns the arguments of the program generated by
+// P2MCProgram, nil is returned if the program is not a magnetic contract
+//
+//	<sellerKey> <standardProgram> <sellerProgram> <ratioDenominator> <ratioNumerator> <requestedAsset> <contract instructions>
+func decodeP2MCProgram(program []byte, insts []vaporvm.Instruction) *vaporvmutil.MagneticContractArgs {
+	if len(insts) < 6 {
+		return nil
+	}
+	for i := range insts[:6] {
+		if !insts[i].IsPushdata() {
+			return nil
+		}
+	}
+
+	standardProgram := insts[1].Data
+	if !vaporsegwit.IsP2WMCScript(standardProgram) {
+		return nil
+	}
+
+	args, err := vaporsegwit.DecodeP2WMCProgram(standardProgram)
+	if err != nil {
+		return nil
+	}
+
+	rebuilt, err := vaporvmutil.P2MCProgram(*args)
+	if err != nil || !bytes.Equal(rebuilt, program) {
+		return nil
+	}
+	return args
+}
+
+// classifyTemplate classifies the program instantiated from the standard
+// contract template
+func classifyTemplate(p *Program, program []byte) {
+	name, args, err := templates.Recognize(program)
+	if err != nil {
+		return
+	}
+
+	p.Type, p.Template = TypeTemplate, name
+	for _, arg := range args {
+		p.TemplateArguments = append(p.TemplateArguments, arg)
+	}
+}
+
+// isProgram tells whether the program is generated by the build function
+// with the hash
+func isProgram(program []byte, build func([]byte) ([]byte, error), hash []byte) bool {
+	rebuilt, err := build(hash)
+	return err == nil && bytes.Equal(rebuilt, program)
+}
+
+func witnessVersion(data []byte) *int64 {
+	version, _ := vm.AsInt64(data)
+	return &version
+}
+
+// retireComment returns the comment of retire program, which is the data
+// following FAIL
+func retireComment(ninsts int, data []byte) chainjson.HexBytes {
+	if ninsts != 2 {
+		return nil
+	}
+	return data
+}
+
+func magneticContract(args *vaporvmutil.MagneticContractArgs) *MagneticContract {
+	return &MagneticContract{
+		RequestedAsset:   args.RequestedAsset.Bytes(),
+		RatioNumerator:   args.RatioNumerator,
+		RatioDenominator: args.RatioDenominator,
+		SellerProgram:    args.SellerProgram,
+		SellerKey:        args.SellerKey,
+	}
+}
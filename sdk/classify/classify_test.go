@@ -0,0 +1,75 @@
+package classify
+
+import (
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519"
+	"github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/bytom/protocol/vm"
+	"github.com/bytom-community/wasm/bytom/protocol/vm/vmutil"
+	vapored25519 "github.com/bytom-community/wasm/vapor/crypto/ed25519"
+	vaporvmutil "github.com/bytom-community/wasm/vapor/protocol/vm/vmutil"
+)
+
+func TestClassifyMultiSig(t *testing.T) {
+	var (
+		pubkeys      []ed25519.PublicKey
+		vaporPubkeys []vapored25519.PublicKey
+	)
+	for i := 0; i < 2; i++ {
+		_, xpub, err := chainkd.NewXKeys(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubkeys = append(pubkeys, xpub.PublicKey())
+		vaporPubkeys = append(vaporPubkeys, vapored25519.PublicKey(xpub.PublicKey()))
+	}
+
+	program, err := vmutil.P2SPMultiSigProgram(pubkeys, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vaporProgram, err := vaporvmutil.P2SPMultiSigProgramWithHeight(vaporPubkeys, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the quorum is pushed as data instead of OP_1
+	quorumAsData := []byte{byte(vm.OP_TXSIGHASH)}
+	quorumAsData = append(quorumAsData, vm.PushdataBytes(pubkeys[0])...)
+	quorumAsData = append(quorumAsData, vm.PushdataBytes(pubkeys[1])...)
+	quorumAsData = append(quorumAsData, byte(vm.OP_DATA_1), 0x01, byte(vm.OP_2), byte(vm.OP_CHECKMULTISIG))
+
+	// the first pubkey is pushed by PUSHDATA1 instead of DATA_32
+	pushdata1 := []byte{byte(vm.OP_TXSIGHASH), byte(vm.OP_PUSHDATA1), ed25519.PublicKeySize}
+	pushdata1 = append(pushdata1, pubkeys[0]...)
+	pushdata1 = append(pushdata1, vm.PushdataBytes(pubkeys[1])...)
+	pushdata1 = append(pushdata1, byte(vm.OP_1), byte(vm.OP_2), byte(vm.OP_CHECKMULTISIG))
+
+	cases := []struct {
+		desc    string
+		chain   string
+		program []byte
+		want    string
+	}{
+		{desc: "bytom multisig", chain: ChainBytom, program: program, want: TypeMultiSig},
+		{desc: "bytom quorum as data", chain: ChainBytom, program: quorumAsData, want: TypeNonStandard},
+		{desc: "bytom pubkey by pushdata1", chain: ChainBytom, program: pushdata1, want: TypeNonStandard},
+		{desc: "vapor multisig", chain: ChainVapor, program: vaporProgram, want: TypeMultiSig},
+		{desc: "vapor quorum as data", chain: ChainVapor, program: quorumAsData, want: TypeNonStandard},
+		{desc: "vapor pubkey by pushdata1", chain: ChainVapor, program: pushdata1, want: TypeNonStandard},
+	}
+
+	for _, c := range cases {
+		p, err := Classify(c.chain, c.program)
+		if err != nil {
+			t.Fatalf("%s: %v", c.desc, err)
+		}
+		if p.Type != c.want {
+			t.Errorf("%s: got type %s, want %s", c.desc, p.Type, c.want)
+		}
+		if p.Type == TypeMultiSig && (p.Quorum != 1 || len(p.Pubkeys) != len(pubkeys)) {
+			t.Errorf("%s: got quorum %d with %d pubkeys, want 1 with %d", c.desc, p.Quorum, len(p.Pubkeys), len(pubkeys))
+		}
+	}
+}
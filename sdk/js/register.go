@@ -23,12 +23,50 @@ func init() {
 	funcs["createAccount"] = base.CreateAccount
 	funcs["createAccountReceiver"] = base.CreateAccountReceiver
 	funcs["signTransaction"] = base.SignTransaction
+	funcs["summarizeTransaction"] = base.SummarizeTransaction
+	funcs["signTemplate"] = base.SignTemplate
+	funcs["signTemplates"] = base.SignTemplates
+	funcs["signPartialTemplate"] = base.SignPartialTemplate
+	funcs["registerSigner"] = base.RegisterSigner
+	funcs["unregisterSigner"] = base.UnregisterSigner
+	funcs["signTemplateWithSigners"] = base.SignTemplateWithSigners
+	funcs["mergeTemplates"] = base.MergeTemplates
+	funcs["verifyTransactionSignatures"] = base.VerifyTransactionSignatures
 	funcs["signMessage"] = base.SignMessage
+	funcs["verifyMessage"] = base.VerifyMessage
+	funcs["signStandardMessage"] = base.SignStandardMessage
+	funcs["verifyStandardMessage"] = base.VerifyStandardMessage
 	funcs["convertArgument"] = base.ConvertArgument
 	funcs["createPubkey"] = base.CreatePubkey
+	funcs["getAddressFromControlProgram"] = base.GetAddressFromControlProgram
+	funcs["assembleProgram"] = base.AssembleProgram
+	funcs["disassembleProgram"] = base.DisassembleProgram
+	funcs["createDebugger"] = base.CreateDebugger
+	funcs["stepDebugger"] = base.StepDebugger
+	funcs["continueDebugger"] = base.ContinueDebugger
+	funcs["closeDebugger"] = base.CloseDebugger
+	funcs["simulateInput"] = base.SimulateInput
+	funcs["compileContract"] = base.CompileContract
+	funcs["instantiateContract"] = base.InstantiateContract
+	funcs["buildClauseArguments"] = base.BuildClauseArguments
+	funcs["getContractTemplates"] = base.GetContractTemplates
+	funcs["recognizeContractTemplate"] = base.RecognizeContractTemplate
+	funcs["classifyProgram"] = base.ClassifyProgram
+	funcs["analyzeProgram"] = base.AnalyzeProgram
+	funcs["wrapP2WSH"] = base.WrapP2WSH
+	funcs["buildP2WSHWitness"] = base.BuildP2WSHWitness
+	funcs["verifyP2WSH"] = base.VerifyP2WSH
+	funcs["exportWatchAccount"] = base.ExportWatchAccount
+	funcs["importWatchAccount"] = base.ImportWatchAccount
+	funcs["createUnsignedTemplate"] = base.CreateUnsignedTemplate
+	funcs["derivePubkey"] = base.DerivePubkey
+	funcs["exportAccountDescriptor"] = base.ExportAccountDescriptor
+	funcs["importAccountDescriptor"] = base.ImportAccountDescriptor
 
 	// vapor
 	funcs["decodeVaporRawTx"] = side.DecodeVaporRawTx
+	funcs["getVaporAddressFromControlProgram"] = side.GetVaporAddressFromControlProgram
+	funcs["signVaporTransaction"] = side.SignVaporTransaction
 }
 
 //Register Register func
@@ -19,6 +19,7 @@ func init() {
 	funcs["createKey"] = base.CreateKey
 	funcs["resetKeyPassword"] = base.ResetKeyPassword
 	funcs["signTransaction"] = base.SignTransaction
+	funcs["summarizeTransaction"] = base.SummarizeTransaction
 }
 
 //Register Register func
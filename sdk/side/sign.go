@@ -0,0 +1,156 @@
+package side
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/pseudohsm"
+	"github.com/bytom-community/wasm/sdk/lib"
+	"github.com/bytom-community/wasm/vapor/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/vapor/common/arithmetic"
+	"github.com/bytom-community/wasm/vapor/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom-community/wasm/vapor/encoding/json"
+	"github.com/bytom-community/wasm/vapor/errors"
+	"github.com/bytom-community/wasm/vapor/protocol/bc"
+	"github.com/bytom-community/wasm/vapor/protocol/bc/types"
+)
+
+// SignInput is the input of vapor raw transaction to sign with the derivation path
+type SignInput struct {
+	Position       uint32               `json:"position"`
+	DerivationPath []chainjson.HexBytes `json:"derivation_path"`
+}
+
+// errTxIDMismatch means the vapor transaction is not the one of the confirmed summary
+var errTxIDMismatch = errors.New("tx_id mismatches the raw transaction")
+
+// RespSignVaporTransaction is the response of SignVaporTransaction
+type RespSignVaporTransaction struct {
+	Template     *txbuilder.Template `json:"transaction"`
+	SignComplete bool                `json:"sign_complete"`
+}
+
+// vaporXPrv convert the decrypted key to the vapor extended private key
+func vaporXPrv(key *pseudohsm.XKey) chainkd.XPrv {
+	var xprv chainkd.XPrv
+	copy(xprv[:], key.XPrv[:])
+	return xprv
+}
+
+// keySignFunc return the txbuilder.SignFunc backed by the vapor extended private key
+func keySignFunc(xprv chainkd.XPrv) txbuilder.SignFunc {
+	return func(_ context.Context, xpub chainkd.XPub, path [][]byte, data [32]byte, _ string) ([]byte, error) {
+		if xpub != xprv.XPub() {
+			return nil, errors.Wrap(txbuilder.ErrKeyNotOwned)
+		}
+
+		if len(path) > 0 {
+			return xprv.Derive(path).Sign(data[:]), nil
+		}
+		return xprv.Sign(data[:]), nil
+	}
+}
+
+// checkTxID check the vapor transaction is the one of the summary confirmed by the user,
+// the tx_id is opt-in so nothing is checked if it is omitted
+func checkTxID(txID string, tx *types.Tx) error {
+	if lib.IsEmpty(txID) {
+		return nil
+	}
+	if tx == nil {
+		return errors.Wrap(txbuilder.ErrMissingRawTx)
+	}
+
+	var confirmedID bc.Hash
+	if err := confirmedID.UnmarshalText([]byte(txID)); err != nil || confirmedID != tx.ID {
+		return errors.WithDetailf(errTxIDMismatch, "tx_id %s", txID)
+	}
+	return nil
+}
+
+// buildVaporTemplate build the template of vapor raw transaction, each input is
+// signed by the key derived with the path
+func buildVaporTemplate(rawTx, inputsJSON string, xpub chainkd.XPub) (*txbuilder.Template, error) {
+	tx := &types.Tx{}
+	if err := tx.UnmarshalText([]byte(rawTx)); err != nil {
+		return nil, err
+	}
+
+	var inputs []*SignInput
+	if err := json.Unmarshal([]byte(inputsJSON), &inputs); err != nil {
+		return nil, err
+	}
+
+	tpl := &txbuilder.Template{Transaction: tx}
+	tpl.Fee, _ = arithmetic.CalculateTxFee(tx)
+	for _, input := range inputs {
+		path := make([][]byte, 0, len(input.DerivationPath))
+		for _, p := range input.DerivationPath {
+			path = append(path, p)
+		}
+
+		si, err := txbuilder.NewP2WPKHInstruction(tx, input.Position, xpub, path)
+		if err != nil {
+			return nil, err
+		}
+		tpl.SigningInstructions = append(tpl.SigningInstructions, si)
+	}
+	return tpl, nil
+}
+
+// SignVaporTransaction sign the vapor transaction template, or the vapor raw transaction
+// with the derivation path of each input, and materialize the witnesses. The sighash of
+// each input is recomputed from the transaction, and the transaction of other id than the
+// tx_id of the confirmed summary is refused if the tx_id is given.
+func SignVaporTransaction(this js.Value, args []js.Value) interface{} {
+	defer lib.EndFunc(args[1])
+	transaction := args[0].Get("transaction").String()
+	rawTx := args[0].Get("raw_transaction").String()
+	inputs := args[0].Get("inputs").String()
+	password := args[0].Get("password").String()
+	keyJSON := args[0].Get("key").String()
+	if lib.IsEmpty(password) || lib.IsEmpty(keyJSON) {
+		args[1].Set("error", "args empty")
+		return nil
+	}
+	if lib.IsEmpty(transaction) && (lib.IsEmpty(rawTx) || lib.IsEmpty(inputs)) {
+		args[1].Set("error", "transaction or raw_transaction and inputs empty")
+		return nil
+	}
+
+	key, err := pseudohsm.DecryptKey([]byte(keyJSON), password)
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	xprv := vaporXPrv(key)
+
+	tpl := &txbuilder.Template{}
+	if !lib.IsEmpty(transaction) {
+		err = json.Unmarshal([]byte(transaction), tpl)
+	} else {
+		tpl, err = buildVaporTemplate(rawTx, inputs, xprv.XPub())
+	}
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	if err := checkTxID(args[0].Get("tx_id").String(), tpl.Transaction); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	if err := txbuilder.Sign(context.Background(), tpl, password, keySignFunc(xprv)); err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+
+	j, err := json.Marshal(&RespSignVaporTransaction{Template: tpl, SignComplete: txbuilder.SignProgress(tpl)})
+	if err != nil {
+		args[1].Set("error", err.Error())
+		return nil
+	}
+	args[1].Set("data", string(j))
+	return nil
+}
@@ -0,0 +1,154 @@
+package side
+
+import (
+	"encoding/json"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/bytom-community/wasm/bytom/blockchain/pseudohsm"
+	bytomchainkd "github.com/bytom-community/wasm/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/vapor/blockchain/txbuilder"
+	"github.com/bytom-community/wasm/vapor/consensus"
+	"github.com/bytom-community/wasm/vapor/crypto"
+	"github.com/bytom-community/wasm/vapor/crypto/ed25519"
+	"github.com/bytom-community/wasm/vapor/protocol/bc"
+	"github.com/bytom-community/wasm/vapor/protocol/bc/types"
+	"github.com/bytom-community/wasm/vapor/protocol/validation"
+	"github.com/bytom-community/wasm/vapor/protocol/vm"
+	"github.com/bytom-community/wasm/vapor/protocol/vm/vmutil"
+	"github.com/pborman/uuid"
+)
+
+const testPassword = "password"
+
+func newTestKey(t *testing.T) (*pseudohsm.XKey, string) {
+	xprv, xpub, err := bytomchainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := &pseudohsm.XKey{ID: uuid.NewRandom(), KeyType: "bytom_kd", Alias: "test", XPrv: xprv, XPub: xpub}
+	keyJSON, err := pseudohsm.EncryptKey(key, testPassword, pseudohsm.LightScryptN, pseudohsm.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(keyJSON)
+}
+
+// callSDK calls the sdk function with the params, and returns the data or the error of the result
+func callSDK(fn func(js.Value, []js.Value) interface{}, params map[string]interface{}) (string, string) {
+	// the optional params are given as empty strings, js.Value.String of the
+	// undefined value is not "undefined" since Go 1.14
+	for _, name := range []string{"transaction", "raw_transaction", "inputs", "tx_id"} {
+		if _, ok := params[name]; !ok {
+			params[name] = ""
+		}
+	}
+
+	endFunc := js.FuncOf(func(js.Value, []js.Value) interface{} { return nil })
+	defer endFunc.Release()
+
+	result := js.Global().Get("Object").New()
+	result.Set("endFunc", endFunc)
+	fn(js.Undefined(), []js.Value{js.ValueOf(params), result})
+	if e := result.Get("error"); e.Type() == js.TypeString {
+		return "", e.String()
+	}
+	return result.Get("data").String(), ""
+}
+
+func TestSignVaporTransaction(t *testing.T) {
+	key, keyJSON := newTestKey(t)
+	xpub := vaporXPrv(key).XPub()
+	path := [][]byte{{1, 0, 0, 0}, {2, 0, 0, 0}}
+	pubkey := xpub.Derive(path).PublicKey()
+	program, err := vmutil.P2WPKHProgram(crypto.Ripemd160(pubkey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := types.NewTx(types.TxData{
+		Version: 1,
+		Inputs: []*types.TxInput{
+			types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 10000, 0, program),
+			types.NewVetoInput(nil, bc.Hash{V0: 2}, *consensus.BTMAssetID, 20000, 1, program, make([]byte, 64)),
+		},
+		Outputs: []*types.TxOutput{types.NewIntraChainOutput(*consensus.BTMAssetID, 25000, []byte{byte(vm.OP_TRUE)})},
+	})
+	rawTx, err := tx.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := `[{"position": 0, "derivation_path": ["01000000", "02000000"]}, {"position": 1, "derivation_path": ["01000000", "02000000"]}]`
+	otherTxID := bc.Hash{V0: 1}
+	_, errMsg := callSDK(SignVaporTransaction, map[string]interface{}{
+		"raw_transaction": string(rawTx),
+		"inputs":          inputs,
+		"password":        testPassword,
+		"key":             keyJSON,
+		"tx_id":           otherTxID.String(),
+	})
+	if want := errTxIDMismatch.Error(); !strings.Contains(errMsg, want) {
+		t.Fatalf("other tx_id: got error %q, want %q", errMsg, want)
+	}
+
+	data, errMsg := callSDK(SignVaporTransaction, map[string]interface{}{
+		"raw_transaction": string(rawTx),
+		"inputs":          inputs,
+		"password":        testPassword,
+		"key":             keyJSON,
+		"tx_id":           tx.ID.String(),
+	})
+	if errMsg != "" {
+		t.Fatal(errMsg)
+	}
+
+	resp := &RespSignVaporTransaction{}
+	if err := json.Unmarshal([]byte(data), resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.SignComplete {
+		t.Fatal("the transaction is not fully signed")
+	}
+
+	signedTx := resp.Template.Transaction
+	for i, input := range signedTx.Inputs {
+		args := input.Arguments()
+		if len(args) != 2 {
+			t.Fatalf("input %d: got %d arguments, want 2", i, len(args))
+		}
+
+		h := signedTx.SigHash(uint32(i))
+		if !ed25519.Verify(pubkey, h.Bytes(), args[0]) {
+			t.Errorf("input %d: the signature is not made over the vapor sighash", i)
+		}
+		if _, err := validation.SimulateInput(&signedTx.TxData, uint32(i), args, 0, consensus.ActiveNetParams.DefaultGasCredit); err != nil {
+			t.Errorf("input %d: the witness is refused by the vapor vm: %v", i, err)
+		}
+	}
+}
+
+func TestSignVaporTransactionCrossChainInput(t *testing.T) {
+	_, keyJSON := newTestKey(t)
+	tx := types.NewTx(types.TxData{
+		Version: 1,
+		Inputs:  []*types.TxInput{types.NewCrossChainInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 10000, 0, 1, nil, nil)},
+		Outputs: []*types.TxOutput{types.NewIntraChainOutput(*consensus.BTMAssetID, 10000, []byte{byte(vm.OP_TRUE)})},
+	})
+	rawTx, err := tx.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, errMsg := callSDK(SignVaporTransaction, map[string]interface{}{
+		"raw_transaction": string(rawTx),
+		"inputs":          `[{"position": 0, "derivation_path": []}]`,
+		"password":        testPassword,
+		"key":             keyJSON,
+	})
+	if want := txbuilder.ErrCrossChainInput.Error(); !strings.Contains(errMsg, want) {
+		t.Fatalf("got error %q, want %q", errMsg, want)
+	}
+}
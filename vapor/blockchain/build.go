@@ -3,13 +3,44 @@ package blockchain
 import (
 	"encoding/hex"
 
+	"github.com/bytom-community/wasm/sdk/classify"
 	"github.com/bytom-community/wasm/vapor/common"
 	"github.com/bytom-community/wasm/vapor/consensus"
 	"github.com/bytom-community/wasm/vapor/consensus/segwit"
 	"github.com/bytom-community/wasm/vapor/protocol/bc"
 	"github.com/bytom-community/wasm/vapor/protocol/bc/types"
+	"github.com/bytom-community/wasm/vapor/protocol/vm"
 )
 
+// the classification of control program
+const (
+	ProgramP2WPKH      = "p2wpkh"
+	ProgramP2WSH       = "p2wsh"
+	ProgramP2PKH       = "p2pkh"
+	ProgramP2SH        = "p2sh"
+	ProgramRetire      = "retire"
+	ProgramCoinbase    = "coinbase"
+	ProgramMultiSig    = "multisig"
+	ProgramMagnetic    = "magnetic"
+	ProgramContract    = "contract"
+	ProgramNonStandard = "nonstandard"
+	ProgramUnknown     = "unknown"
+)
+
+// programTypes maps the types of the classifier to the classification
+var programTypes = map[string]string{
+	classify.TypeP2WPKH:   ProgramP2WPKH,
+	classify.TypeP2WSH:    ProgramP2WSH,
+	classify.TypeP2PKH:    ProgramP2PKH,
+	classify.TypeP2SH:     ProgramP2SH,
+	classify.TypeRetire:   ProgramRetire,
+	classify.TypeCoinbase: ProgramCoinbase,
+	classify.TypeMultiSig: ProgramMultiSig,
+	classify.TypeP2WMC:    ProgramMagnetic,
+	classify.TypeP2MC:     ProgramMagnetic,
+	classify.TypeTemplate: ProgramContract,
+}
+
 // BuildAnnotatedInput build the annotated input.
 func BuildAnnotatedInput(tx *types.Tx, i int) *AnnotatedInput {
 	orig := tx.Inputs[i]
@@ -108,7 +139,12 @@ func getAddressFromControlProgram(prog []byte, isMainchain bool) string {
 	if isMainchain {
 		netParams = consensus.BytomMainNetParams(&consensus.MainNetParams)
 	}
+	return GetAddressFromControlProgram(prog, netParams)
+}
 
+// GetAddressFromControlProgram return the address of the standard control program,
+// and empty string for the non-standard one
+func GetAddressFromControlProgram(prog []byte, netParams *consensus.Params) string {
 	if segwit.IsP2WPKHScript(prog) {
 		if pubHash, err := segwit.GetHashFromStandardProg(prog); err == nil {
 			return buildP2PKHAddress(pubHash, netParams)
@@ -121,6 +157,24 @@ func getAddressFromControlProgram(prog []byte, isMainchain bool) string {
 	return ""
 }
 
+// GetControlProgramType return the classification of control program, the
+// contract is the program instantiated from the standard contract template,
+// and the program can't be parsed is unknown
+func GetControlProgramType(prog []byte) string {
+	if _, err := vm.ParseProgram(prog); err != nil {
+		return ProgramUnknown
+	}
+
+	p, err := classify.Classify(classify.ChainVapor, prog)
+	if err != nil {
+		return ProgramUnknown
+	}
+	if t, ok := programTypes[p.Type]; ok {
+		return t
+	}
+	return ProgramNonStandard
+}
+
 func buildP2PKHAddress(pubHash []byte, netParams *consensus.Params) string {
 	address, err := common.NewAddressWitnessPubKeyHash(pubHash, netParams)
 	if err != nil {
@@ -0,0 +1,45 @@
+package txbuilder
+
+import (
+	"encoding/json"
+
+	chainjson "github.com/bytom-community/wasm/vapor/encoding/json"
+	"github.com/bytom-community/wasm/vapor/errors"
+)
+
+// DataWitness used sign transaction
+type DataWitness chainjson.HexBytes
+
+func (dw DataWitness) materialize(args *[][]byte) error {
+	*args = append(*args, dw)
+	return nil
+}
+
+// MarshalJSON marshal DataWitness
+func (dw DataWitness) MarshalJSON() ([]byte, error) {
+	x := struct {
+		Type  string             `json:"type"`
+		Value chainjson.HexBytes `json:"value"`
+	}{
+		Type:  "data",
+		Value: chainjson.HexBytes(dw),
+	}
+	return json.Marshal(x)
+}
+
+// UnmarshalJSON unmarshal DataWitness
+func (dw *DataWitness) UnmarshalJSON(b []byte) error {
+	var x struct {
+		Type  string             `json:"type"`
+		Value chainjson.HexBytes `json:"value"`
+	}
+	if err := json.Unmarshal(b, &x); err != nil {
+		return err
+	}
+	if x.Type != "data" {
+		return errors.WithDetailf(ErrBadWitnessComponent, "type %s is not data", x.Type)
+	}
+
+	*dw = DataWitness(x.Value)
+	return nil
+}
@@ -0,0 +1,102 @@
+package txbuilder
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bytom-community/wasm/vapor/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom-community/wasm/vapor/encoding/json"
+	"github.com/bytom-community/wasm/vapor/errors"
+)
+
+type (
+	// RawTxSigWitness signs the sighash of the input, the vapor accounts
+	// don't involve signature programs.
+	RawTxSigWitness struct {
+		Quorum int                  `json:"quorum"`
+		Keys   []keyID              `json:"keys"`
+		Sigs   []chainjson.HexBytes `json:"signatures"`
+	}
+
+	keyID struct {
+		XPub           chainkd.XPub         `json:"xpub"`
+		DerivationPath []chainjson.HexBytes `json:"derivation_path"`
+	}
+)
+
+func (sw *RawTxSigWitness) sign(ctx context.Context, tpl *Template, index uint32, auth string, signFn SignFunc) error {
+	if len(sw.Sigs) < len(sw.Keys) {
+		// Each key in sw.Keys may produce a signature in sw.Sigs. Make
+		// sure there are enough slots in sw.Sigs and that we preserve any
+		// sigs already present.
+		newSigs := make([]chainjson.HexBytes, len(sw.Keys))
+		copy(newSigs, sw.Sigs)
+		sw.Sigs = newSigs
+	}
+	for i, keyID := range sw.Keys {
+		if len(sw.Sigs[i]) > 0 {
+			// Already have a signature for this key
+			continue
+		}
+		path := make([][]byte, len(keyID.DerivationPath))
+		for i, p := range keyID.DerivationPath {
+			path[i] = p
+		}
+		sigBytes, err := signFn(ctx, keyID.XPub, path, tpl.Hash(tpl.SigningInstructions[index].Position).Byte32(), auth)
+		if errors.Root(err) == ErrKeyNotOwned {
+			continue
+		}
+		if err != nil {
+			return errors.WithDetailf(err, "computing signature %d", i)
+		}
+
+		sw.Sigs[i] = sigBytes
+	}
+	return nil
+}
+
+func (sw RawTxSigWitness) materialize(args *[][]byte) error {
+	var nsigs int
+	for i := 0; i < len(sw.Sigs) && nsigs < sw.Quorum; i++ {
+		if len(sw.Sigs[i]) > 0 {
+			*args = append(*args, sw.Sigs[i])
+			nsigs++
+		}
+	}
+	return nil
+}
+
+// MarshalJSON convert struct to json
+func (sw RawTxSigWitness) MarshalJSON() ([]byte, error) {
+	obj := struct {
+		Type   string               `json:"type"`
+		Quorum int                  `json:"quorum"`
+		Keys   []keyID              `json:"keys"`
+		Sigs   []chainjson.HexBytes `json:"signatures"`
+	}{
+		Type:   "raw_tx_signature",
+		Quorum: sw.Quorum,
+		Keys:   sw.Keys,
+		Sigs:   sw.Sigs,
+	}
+	return json.Marshal(obj)
+}
+
+// UnmarshalJSON convert json to struct
+func (sw *RawTxSigWitness) UnmarshalJSON(b []byte) error {
+	var obj struct {
+		Type   string               `json:"type"`
+		Quorum int                  `json:"quorum"`
+		Keys   []keyID              `json:"keys"`
+		Sigs   []chainjson.HexBytes `json:"signatures"`
+	}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	if obj.Type != "raw_tx_signature" {
+		return errors.WithDetailf(ErrBadWitnessComponent, "type %s is not raw_tx_signature", obj.Type)
+	}
+
+	sw.Quorum, sw.Keys, sw.Sigs = obj.Quorum, obj.Keys, obj.Sigs
+	return nil
+}
@@ -0,0 +1,130 @@
+package txbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/bytom-community/wasm/vapor/consensus/segwit"
+	"github.com/bytom-community/wasm/vapor/crypto"
+	"github.com/bytom-community/wasm/vapor/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom-community/wasm/vapor/encoding/json"
+	"github.com/bytom-community/wasm/vapor/errors"
+	"github.com/bytom-community/wasm/vapor/protocol/bc/types"
+)
+
+// AddRawWitnessKeys adds a RawTxSigWitness with the given quorum and
+// list of keys derived by applying the derivation path to each of the
+// xpubs.
+func (si *SigningInstruction) AddRawWitnessKeys(xpubs []chainkd.XPub, path [][]byte, quorum int) {
+	si.WitnessComponents = append(si.WitnessComponents, &RawTxSigWitness{
+		Quorum: quorum,
+		Keys:   keyIDs(xpubs, path),
+	})
+}
+
+// AddDataWitness append data to the witness array
+func (si *SigningInstruction) AddDataWitness(data chainjson.HexBytes) {
+	si.WitnessComponents = append(si.WitnessComponents, DataWitness(data))
+}
+
+// NewP2WPKHInstruction build the signing instruction of the P2WPKH input at the
+// position, the spend and veto inputs are signed in the same way. The cross-chain
+// input is checked against the federation script by vapor rather than its control
+// program, so it is refused with ErrCrossChainInput.
+func NewP2WPKHInstruction(tx *types.Tx, position uint32, xpub chainkd.XPub, path [][]byte) (*SigningInstruction, error) {
+	if position >= uint32(len(tx.Inputs)) {
+		return nil, errors.WithDetailf(ErrBadTxInputIdx, "missing tx input %d", position)
+	}
+
+	input := tx.Inputs[position]
+	switch input.InputType() {
+	case types.CrossChainInputType:
+		return nil, errors.WithDetailf(ErrCrossChainInput, "input %d", position)
+	case types.CoinbaseInputType:
+		return nil, errors.WithDetailf(ErrBadControlProgram, "input %d is coinbase", position)
+	}
+
+	pubkey := xpub.Derive(path).PublicKey()
+	program := input.ControlProgram()
+	if !segwit.IsP2WPKHScript(program) {
+		return nil, errors.WithDetailf(ErrBadControlProgram, "input %d is not P2WPKH", position)
+	}
+	if pubHash, err := segwit.GetHashFromStandardProg(program); err != nil || !bytes.Equal(pubHash, crypto.Ripemd160(pubkey)) {
+		return nil, errors.WithDetailf(ErrBadControlProgram, "input %d is not controlled by the key", position)
+	}
+
+	si := &SigningInstruction{Position: position}
+	si.AddRawWitnessKeys([]chainkd.XPub{xpub}, path, 1)
+	si.AddDataWitness(chainjson.HexBytes(pubkey))
+	return si, nil
+}
+
+func keyIDs(xpubs []chainkd.XPub, path [][]byte) []keyID {
+	hexPath := make([]chainjson.HexBytes, 0, len(path))
+	for _, p := range path {
+		hexPath = append(hexPath, p)
+	}
+
+	keys := make([]keyID, 0, len(xpubs))
+	for _, xpub := range xpubs {
+		keys = append(keys, keyID{XPub: xpub, DerivationPath: hexPath})
+	}
+	return keys
+}
+
+// SigningInstruction gives directions for signing inputs in a vapor TxTemplate.
+type SigningInstruction struct {
+	Position          uint32             `json:"position"`
+	WitnessComponents []witnessComponent `json:"witness_components,omitempty"`
+}
+
+// UnmarshalJSON unmarshal SigningInstruction, the witness components are
+// decoded by their type tag
+func (si *SigningInstruction) UnmarshalJSON(b []byte) error {
+	var pre struct {
+		Position          uint32            `json:"position"`
+		WitnessComponents []json.RawMessage `json:"witness_components"`
+	}
+	if err := json.Unmarshal(b, &pre); err != nil {
+		return err
+	}
+
+	si.Position = pre.Position
+	si.WitnessComponents = make([]witnessComponent, 0, len(pre.WitnessComponents))
+	for i, wc := range pre.WitnessComponents {
+		var t struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(wc, &t); err != nil {
+			return errors.Wrapf(err, "unmarshaling error on witness component %d, input %s", i, wc)
+		}
+
+		switch t.Type {
+		case "data":
+			var dw DataWitness
+			if err := json.Unmarshal(wc, &dw); err != nil {
+				return errors.Wrapf(err, "unmarshaling error on witness component %d, type data, input %s", i, wc)
+			}
+			si.WitnessComponents = append(si.WitnessComponents, dw)
+
+		case "raw_tx_signature":
+			sw := &RawTxSigWitness{}
+			if err := json.Unmarshal(wc, sw); err != nil {
+				return errors.Wrapf(err, "unmarshaling error on witness component %d, type raw_tx_signature, input %s", i, wc)
+			}
+			si.WitnessComponents = append(si.WitnessComponents, sw)
+
+		default:
+			return errors.WithDetailf(ErrBadWitnessComponent, "witness component %d has unknown type '%s'", i, t.Type)
+		}
+	}
+	return nil
+}
+
+// witnessComponent is the abstract type for the parts of a
+// SigningInstruction.  Each witnessComponent produces one or more
+// arguments for a VM program via its materialize method. Concrete
+// witnessComponent types include RawTxSigWitness and DataWitness.
+type witnessComponent interface {
+	materialize(*[][]byte) error
+}
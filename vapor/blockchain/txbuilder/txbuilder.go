@@ -0,0 +1,48 @@
+// Package txbuilder signs the vapor transaction template and materializes
+// the witnesses of the inputs.
+package txbuilder
+
+import (
+	"context"
+
+	"github.com/bytom-community/wasm/vapor/errors"
+)
+
+// errors
+var (
+	//ErrBadTxInputIdx means unsigned tx input
+	ErrBadTxInputIdx = errors.New("unsigned tx missing input")
+	// ErrMissingRawTx means missing transaction
+	ErrMissingRawTx = errors.New("missing raw tx")
+	// ErrBadInstructionCount means too many signing instructions compare with inputs
+	ErrBadInstructionCount = errors.New("too many signing instructions in template")
+	// ErrBadWitnessComponent means the type of witness component is unknown
+	ErrBadWitnessComponent = errors.New("invalid witness component")
+	// ErrBadControlProgram means the control program of input can't be signed by the key
+	ErrBadControlProgram = errors.New("control program of input can't be signed by the key")
+	// ErrCrossChainInput means the cross-chain input is checked against the federation script instead of a key
+	ErrCrossChainInput = errors.New("cross-chain input is signed by the federation")
+)
+
+// Sign will try to sign all the witness
+func Sign(ctx context.Context, tpl *Template, auth string, signFn SignFunc) error {
+	if tpl.Transaction == nil {
+		return errors.Wrap(ErrMissingRawTx)
+	}
+
+	for i, sigInst := range tpl.SigningInstructions {
+		if sigInst.Position >= uint32(len(tpl.Transaction.Inputs)) {
+			return errors.WithDetailf(ErrBadTxInputIdx, "signing instruction %d references missing tx input %d", i, sigInst.Position)
+		}
+
+		for j, wc := range sigInst.WitnessComponents {
+			if sw, ok := wc.(*RawTxSigWitness); ok {
+				err := sw.sign(ctx, tpl, uint32(i), auth, signFn)
+				if err != nil {
+					return errors.WithDetailf(err, "adding signature(s) to raw-signature witness component %d of input %d", j, i)
+				}
+			}
+		}
+	}
+	return materializeWitnesses(tpl)
+}
@@ -0,0 +1,126 @@
+package txbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bytom-community/wasm/vapor/consensus"
+	"github.com/bytom-community/wasm/vapor/crypto"
+	"github.com/bytom-community/wasm/vapor/crypto/ed25519"
+	"github.com/bytom-community/wasm/vapor/crypto/ed25519/chainkd"
+	"github.com/bytom-community/wasm/vapor/errors"
+	"github.com/bytom-community/wasm/vapor/protocol/bc"
+	"github.com/bytom-community/wasm/vapor/protocol/bc/types"
+	"github.com/bytom-community/wasm/vapor/protocol/validation"
+	"github.com/bytom-community/wasm/vapor/protocol/vm"
+	"github.com/bytom-community/wasm/vapor/protocol/vm/vmutil"
+)
+
+func testSignFunc(xprv chainkd.XPrv) SignFunc {
+	return func(_ context.Context, xpub chainkd.XPub, path [][]byte, data [32]byte, _ string) ([]byte, error) {
+		if xpub != xprv.XPub() {
+			return nil, errors.Wrap(ErrKeyNotOwned)
+		}
+		return xprv.Derive(path).Sign(data[:]), nil
+	}
+}
+
+func p2wpkhProgram(t *testing.T, xpub chainkd.XPub, path [][]byte) []byte {
+	program, err := vmutil.P2WPKHProgram(crypto.Ripemd160(xpub.Derive(path).PublicKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program
+}
+
+func TestSignP2WPKH(t *testing.T) {
+	xprv, xpub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := [][]byte{{1, 0, 0, 0}, {2, 0, 0, 0}}
+	program := p2wpkhProgram(t, xpub, path)
+	tx := types.NewTx(types.TxData{
+		Version: 1,
+		Inputs: []*types.TxInput{
+			types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 10000, 0, program),
+			types.NewVetoInput(nil, bc.Hash{V0: 2}, *consensus.BTMAssetID, 20000, 1, program, make([]byte, 64)),
+		},
+		Outputs: []*types.TxOutput{types.NewIntraChainOutput(*consensus.BTMAssetID, 25000, []byte{byte(vm.OP_TRUE)})},
+	})
+
+	tpl := &Template{Transaction: tx}
+	for i := range tx.Inputs {
+		si, err := NewP2WPKHInstruction(tx, uint32(i), xpub, path)
+		if err != nil {
+			t.Fatalf("input %d: %v", i, err)
+		}
+		tpl.SigningInstructions = append(tpl.SigningInstructions, si)
+	}
+
+	if err := Sign(context.Background(), tpl, "", testSignFunc(xprv)); err != nil {
+		t.Fatal(err)
+	}
+	if !SignProgress(tpl) {
+		t.Fatal("the template is not fully signed")
+	}
+
+	pubkey := xpub.Derive(path).PublicKey()
+	for i, input := range tpl.Transaction.Inputs {
+		args := input.Arguments()
+		if len(args) != 2 {
+			t.Fatalf("input %d: got %d arguments, want 2", i, len(args))
+		}
+
+		h := tpl.Transaction.SigHash(uint32(i))
+		if !ed25519.Verify(pubkey, h.Bytes(), args[0]) {
+			t.Errorf("input %d: the signature is not made over the vapor sighash", i)
+		}
+		if _, err := validation.SimulateInput(&tpl.Transaction.TxData, uint32(i), args, 0, consensus.ActiveNetParams.DefaultGasCredit); err != nil {
+			t.Errorf("input %d: the witness is refused by the vapor vm: %v", i, err)
+		}
+	}
+}
+
+func TestNewP2WPKHInstructionErrors(t *testing.T) {
+	_, xpub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherXPub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	program := p2wpkhProgram(t, xpub, nil)
+	tx := types.NewTx(types.TxData{
+		Version: 1,
+		Inputs: []*types.TxInput{
+			types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 10000, 0, program),
+			types.NewCrossChainInput(nil, bc.Hash{V0: 2}, *consensus.BTMAssetID, 20000, 0, 1, nil, nil),
+			types.NewCoinbaseInput(nil),
+			types.NewSpendInput(nil, bc.Hash{V0: 3}, *consensus.BTMAssetID, 10000, 0, []byte{byte(vm.OP_TRUE)}),
+		},
+	})
+
+	cases := []struct {
+		desc     string
+		position uint32
+		xpub     chainkd.XPub
+		want     error
+	}{
+		{desc: "P2WPKH input", position: 0, xpub: xpub},
+		{desc: "other key", position: 0, xpub: otherXPub, want: ErrBadControlProgram},
+		{desc: "cross-chain input", position: 1, xpub: xpub, want: ErrCrossChainInput},
+		{desc: "coinbase input", position: 2, xpub: xpub, want: ErrBadControlProgram},
+		{desc: "non-P2WPKH input", position: 3, xpub: xpub, want: ErrBadControlProgram},
+		{desc: "missing input", position: 4, xpub: xpub, want: ErrBadTxInputIdx},
+	}
+
+	for _, c := range cases {
+		if _, err := NewP2WPKHInstruction(tx, c.position, c.xpub, nil); errors.Root(err) != c.want {
+			t.Errorf("%s: got error %v, want %v", c.desc, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package txbuilder
+
+import (
+	"github.com/bytom-community/wasm/vapor/protocol/bc"
+	"github.com/bytom-community/wasm/vapor/protocol/bc/types"
+)
+
+// Template represents a partially- or fully-signed vapor transaction.
+type Template struct {
+	Transaction         *types.Tx             `json:"raw_transaction"`
+	SigningInstructions []*SigningInstruction `json:"signing_instructions"`
+	Fee                 uint64                `json:"fee"`
+
+	// AllowAdditional is always false for the raw transaction signature,
+	// it is kept for the compatibility of the template.
+	AllowAdditional bool `json:"allow_additional_actions"`
+}
+
+// Hash return sign hash
+func (t *Template) Hash(idx uint32) bc.Hash {
+	return t.Transaction.SigHash(idx)
+}
@@ -1,9 +1,11 @@
 package segwit
 
 import (
+	"bytes"
 	"errors"
 
 	"github.com/bytom-community/wasm/vapor/consensus"
+	"github.com/bytom-community/wasm/vapor/crypto/sha3pool"
 	"github.com/bytom-community/wasm/vapor/protocol/bc"
 	"github.com/bytom-community/wasm/vapor/protocol/vm"
 	"github.com/bytom-community/wasm/vapor/protocol/vm/vmutil"
@@ -163,6 +165,50 @@ func GetHashFromStandardProg(prog []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(insts) < 2 {
+		return nil, errors.New("invalid standard program")
+	}
 
 	return insts[1].Data, nil
 }
+
+// ErrBadP2WSHWitness is returned when the witness doesn't reveal the program
+// of the P2WSH script hash
+var ErrBadP2WSHWitness = errors.New("bad P2WSH witness")
+
+// P2WSHWitness returns the witness arguments spending the P2WSH program of
+// the program, the program is appended after the clause arguments and is
+// revealed at spend time
+func P2WSHWitness(program []byte, args [][]byte) [][]byte {
+	witness := make([][]byte, 0, len(args)+1)
+	witness = append(witness, args...)
+	return append(witness, program)
+}
+
+// DecodeP2WSHWitness returns the program revealed by the witness spending the
+// standard P2WSH program and the clause arguments, the script hash of the
+// program must match the P2WSH program
+func DecodeP2WSHWitness(prog []byte, witness [][]byte) ([]byte, [][]byte, error) {
+	if !IsP2WSHScript(prog) {
+		return nil, nil, errors.New("invalid P2WSH program")
+	}
+
+	insts, err := vm.ParseProgram(prog)
+	if err != nil {
+		return nil, nil, err
+	}
+	if insts[0].Op != vm.OP_0 {
+		return nil, nil, errors.New("unknow P2WSH version number")
+	}
+	if len(witness) == 0 {
+		return nil, nil, ErrBadP2WSHWitness
+	}
+
+	program := witness[len(witness)-1]
+	var scriptHash [32]byte
+	sha3pool.Sum256(scriptHash[:], program)
+	if !bytes.Equal(scriptHash[:], insts[1].Data) {
+		return nil, nil, ErrBadP2WSHWitness
+	}
+	return program, witness[:len(witness)-1], nil
+}
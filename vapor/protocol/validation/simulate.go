@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"github.com/bytom-community/wasm/vapor/errors"
+	"github.com/bytom-community/wasm/vapor/protocol/bc"
+	"github.com/bytom-community/wasm/vapor/protocol/bc/types"
+	"github.com/bytom-community/wasm/vapor/protocol/vm"
+)
+
+var (
+	// ErrBadInputIdx is returned when the input index is out of the range of the transaction inputs
+	ErrBadInputIdx = errors.New("input index out of range")
+	// ErrUnsupportedInput is returned when the program of input can not be simulated
+	ErrUnsupportedInput = errors.New("unsupported input type to simulate")
+)
+
+// InputContext builds the vm.Context of the input at position of the proposed
+// transaction, as if the transaction is packed into the block at blockHeight
+// and the input is unlocked by args.
+func InputContext(txData *types.TxData, position uint32, args [][]byte, blockHeight uint64) (*vm.Context, error) {
+	if int(position) >= len(txData.Inputs) {
+		return nil, errors.WithDetailf(ErrBadInputIdx, "position %d, %d inputs", position, len(txData.Inputs))
+	}
+
+	tx := types.MapTx(txData)
+	entry := tx.Entries[tx.InputIDs[position]]
+
+	var prog *bc.Program
+	switch e := entry.(type) {
+	case *bc.Spend:
+		prog = tx.Entries[*e.SpentOutputId].(*bc.IntraChainOutput).ControlProgram
+
+	case *bc.VetoInput:
+		prog = tx.Entries[*e.SpentOutputId].(*bc.VoteOutput).ControlProgram
+
+	default:
+		return nil, errors.WithDetailf(ErrUnsupportedInput, "entry type %T", entry)
+	}
+
+	return NewTxVMContext(tx, blockHeight, entry, prog, args), nil
+}
+
+// SimulateInput evaluates the program of the input at position of the
+// proposed transaction with args, and returns the gas left. The transaction
+// is not required to be valid, so any clause of a contract can be tried out
+// with the chosen arguments and outputs.
+func SimulateInput(txData *types.TxData, position uint32, args [][]byte, blockHeight uint64, gasLimit int64) (int64, error) {
+	context, err := InputContext(txData, position, args, blockHeight)
+	if err != nil {
+		return 0, err
+	}
+
+	return vm.Verify(context, gasLimit)
+}
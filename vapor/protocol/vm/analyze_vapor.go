@@ -0,0 +1,7 @@
+package vm
+
+// the stack effects of the opcodes only in vapor, the analyzer is shared
+// with bytom
+func init() {
+	stackEffects[OP_MULFRACTION] = stackEffect{3, 1, 8}
+}
@@ -114,9 +114,27 @@ func Assemble(s string) (res []byte, err error) {
 	return res, nil
 }
 
-func Disassemble(prog []byte) (string, error) {
+// DisassembledOp is an op of the disassembled program.
+type DisassembledOp struct {
+	Offset uint32
+	Op     Op
+	Data   []byte
+
+	// Label is the jump-target label of the op location, empty if it is not a
+	// jump target.
+	Label string
+
+	// Target and TargetLabel are the location and the label the JUMP or JUMPIF
+	// op jumps to.
+	Target      uint32
+	TargetLabel string
+}
+
+// DisassembleOps converts the program into ops, the jump targets are labeled and
+// the labels are returned by location, a label may refer to the end of program.
+func DisassembleOps(prog []byte) ([]*DisassembledOp, map[uint32]string, error) {
 	var (
-		insts []Instruction
+		dops []*DisassembledOp
 
 		// maps program locations (used as jump targets) to a label for each
 		labels = make(map[uint32]string)
@@ -126,8 +144,10 @@ func Disassemble(prog []byte) (string, error) {
 	for i := uint32(0); i < uint32(len(prog)); {
 		inst, err := ParseOp(prog, i)
 		if err != nil {
-			return "", err
+			return nil, nil, err
 		}
+
+		dop := &DisassembledOp{Offset: i, Op: inst.Op, Data: inst.Data}
 		switch inst.Op {
 		case OP_JUMP, OP_JUMPIF:
 			addr := binary.LittleEndian.Uint32(inst.Data)
@@ -139,39 +159,48 @@ func Disassemble(prog []byte) (string, error) {
 				}
 				labels[addr] = label
 			}
+			dop.Target, dop.TargetLabel = addr, labels[addr]
 		}
-		insts = append(insts, inst)
+		dops = append(dops, dop)
 		i += inst.Len
 	}
 
-	var (
-		loc  uint32
-		strs []string
-	)
+	// second pass: label the jump targets
+	for _, dop := range dops {
+		dop.Label = labels[dop.Offset]
+	}
+	return dops, labels, nil
+}
+
+// Disassemble converts the program into the string form of Assemble, the jump
+// targets are labeled.
+func Disassemble(prog []byte) (string, error) {
+	dops, labels, err := DisassembleOps(prog)
+	if err != nil {
+		return "", err
+	}
 
-	for _, inst := range insts {
-		if label, ok := labels[loc]; ok {
-			strs = append(strs, "$"+label)
+	var strs []string
+	for _, dop := range dops {
+		if dop.Label != "" {
+			strs = append(strs, "$"+dop.Label)
 		}
 
 		var str string
-		switch inst.Op {
+		switch dop.Op {
 		case OP_JUMP, OP_JUMPIF:
-			addr := binary.LittleEndian.Uint32(inst.Data)
-			str = fmt.Sprintf("%s:$%s", inst.Op.String(), labels[addr])
+			str = fmt.Sprintf("%s:$%s", dop.Op.String(), dop.TargetLabel)
 		default:
-			if len(inst.Data) > 0 {
-				str = fmt.Sprintf("0x%x", inst.Data)
+			if len(dop.Data) > 0 {
+				str = fmt.Sprintf("0x%x", dop.Data)
 			} else {
-				str = inst.Op.String()
+				str = dop.Op.String()
 			}
 		}
 		strs = append(strs, str)
-
-		loc += inst.Len
 	}
 
-	if label, ok := labels[loc]; ok {
+	if label, ok := labels[uint32(len(prog))]; ok {
 		strs = append(strs, "$"+label)
 	}
 
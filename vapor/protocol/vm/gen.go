@@ -0,0 +1,33 @@
+// +build ignore
+
+// gen.go generates the VM files shared with bytom from the bytom sources, the
+// import paths of bytom packages are replaced by the vapor ones.
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+)
+
+const bytomVMDir = "../../../bytom/protocol/vm"
+
+// sharedFiles are the files of bytom VM used by vapor without change
+var sharedFiles = []string{"analyze.go", "debug.go"}
+
+func main() {
+	for _, name := range sharedFiles {
+		src, err := ioutil.ReadFile(filepath.Join(bytomVMDir, name))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		buf.WriteString("// Code generated by gen.go from bytom/protocol/vm/" + name + ". DO NOT EDIT.\n\n")
+		buf.Write(bytes.Replace(src, []byte("github.com/bytom-community/wasm/bytom/"), []byte("github.com/bytom-community/wasm/vapor/"), -1))
+		if err := ioutil.WriteFile(name, buf.Bytes(), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
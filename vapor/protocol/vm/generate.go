@@ -0,0 +1,4 @@
+package vm
+
+// The files shared with bytom VM are generated from the bytom sources.
+//go:generate go run gen.go
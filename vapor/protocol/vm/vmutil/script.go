@@ -128,6 +128,42 @@ func P2SPMultiSigProgramWithHeight(pubkeys []ed25519.PublicKey, nrequired int, b
 	return builder.Build()
 }
 
+// DecodeP2SPMultiSigProgram parses the program generated by P2SPMultiSigProgram
+// and returns its public keys and quorum
+func DecodeP2SPMultiSigProgram(program []byte) ([]ed25519.PublicKey, int, error) {
+	insts, err := vm.ParseProgram(program)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(insts) < 5 || insts[0].Op != vm.OP_TXSIGHASH || insts[len(insts)-1].Op != vm.OP_CHECKMULTISIG {
+		return nil, 0, ErrMultisigFormat
+	}
+
+	npubkeys, err := vm.AsInt64(insts[len(insts)-2].Data)
+	if err != nil {
+		return nil, 0, err
+	}
+	nrequired, err := vm.AsInt64(insts[len(insts)-3].Data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := checkMultiSigParams(nrequired, npubkeys); err != nil {
+		return nil, 0, err
+	}
+	if int(npubkeys) != len(insts)-4 {
+		return nil, 0, ErrMultisigFormat
+	}
+
+	pubkeys := make([]ed25519.PublicKey, 0, npubkeys)
+	for _, inst := range insts[1 : len(insts)-3] {
+		if !inst.IsPushdata() || len(inst.Data) != ed25519.PublicKeySize {
+			return nil, 0, ErrMultisigFormat
+		}
+		pubkeys = append(pubkeys, ed25519.PublicKey(inst.Data))
+	}
+	return pubkeys, int(nrequired), nil
+}
+
 func checkMultiSigParams(nrequired, npubkeys int64) error {
 	if nrequired < 0 {
 		return errors.WithDetail(ErrBadValue, "negative quorum")